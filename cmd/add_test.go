@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"taskeru/internal"
+)
+
+func TestAddCommandKeepsWordsWhichAreNoTasks(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	require.NoError(t, taskFile.AddTasks([]internal.Task{
+		*internal.NewTask("First"), *internal.NewTask("Second"), *internal.NewTask("Third"),
+	}))
+
+	for _, title := range []string{"Fix bug in after:0 release", "Call dad after:face check"} {
+		output := captureStdout(t, func() {
+			require.NoError(t, AddCommand(taskFile, []string{title}))
+		})
+		require.Equal(t, "Task added: "+title+"\n", output)
+
+		tasks, err := taskFile.LoadTasks()
+		require.NoError(t, err)
+		added := tasks[len(tasks)-1]
+		require.Equal(t, title, added.Title)
+		require.Empty(t, added.BlockedBy)
+		require.Equal(t, internal.StatusTODO, added.Status)
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"taskeru/internal"
+)
+
+// ArchiveCommand moves old completed tasks to the archive: taskeru archive [--older-than 30d]
+func ArchiveCommand(taskFile *internal.TaskFile, args []string) error {
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Without --older-than, the after_days setting of the config file decides
+	age := time.Duration(config.Archive.AfterDays) * 24 * time.Hour
+	olderThan := false
+	for i := 0; i < len(args); i++ {
+		value := ""
+		switch {
+		case args[i] == "--older-than" && i+1 < len(args):
+			value = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--older-than="):
+			value = strings.TrimPrefix(args[i], "--older-than=")
+		default:
+			return fmt.Errorf("usage: archive [--older-than <age>]")
+		}
+		if age, err = internal.ParseAge(value); err != nil {
+			return err
+		}
+		olderThan = true
+	}
+	if !olderThan && config.Archive.AfterDays == 0 {
+		return fmt.Errorf("archiving is disabled by after_days = 0 in the config file, use --older-than <age>")
+	}
+
+	count, err := taskFile.ArchiveTasks(time.Now().Add(-age))
+	if err != nil {
+		return fmt.Errorf("failed to archive tasks: %w", err)
+	}
+	fmt.Printf("Archived %d completed task", count)
+	if count != 1 {
+		fmt.Print("s")
+	}
+	fmt.Printf(" to %s\n", taskFile.ArchiveDir())
+	return nil
+}
+
+// autoArchiveInterval is how often the web server archives old completed tasks
+const autoArchiveInterval = time.Hour
+
+// autoArchive archives old completed tasks before running a command, if enabled in the config file
+func autoArchive(taskFile *internal.TaskFile) {
+	config, err := internal.LoadConfig()
+	if !config.Archive.Auto {
+		return
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: automatic archiving is skipped: %v\n", err)
+		return
+	}
+	if _, err := taskFile.AutoArchive(config); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: failed to archive tasks: %v\n", err)
+	}
+}
+
+// autoArchivePeriodically archives old completed tasks every interval until ctx is done,
+// for the web server, which runs longer than tasks take to get old
+func autoArchivePeriodically(ctx context.Context, taskFile *internal.TaskFile, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			autoArchive(taskFile)
+		}
+	}
+}
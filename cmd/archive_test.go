@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"taskeru/internal"
+)
+
+func addArchivedTask(t *testing.T, taskFile *internal.TaskFile, title string, completedAt time.Time) {
+	t.Helper()
+	task := internal.NewTask(title)
+	task.Status = internal.StatusDONE
+	task.CompletedAt = &completedAt
+	require.NoError(t, taskFile.AddTask(task))
+	_, err := taskFile.ArchiveTasks(completedAt.Add(time.Second))
+	require.NoError(t, err)
+}
+
+func TestArchiveCommand(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	old := internal.NewTask("Old done task")
+	old.Status = internal.StatusDONE
+	completedAt := time.Now().AddDate(0, 0, -10)
+	old.CompletedAt = &completedAt
+	require.NoError(t, taskFile.AddTasks([]internal.Task{*old, *internal.NewTask("Open task")}))
+
+	// Younger than after_days (30) by default
+	output := captureStdout(t, func() {
+		require.NoError(t, ArchiveCommand(taskFile, nil))
+	})
+	require.Contains(t, output, "Archived 0 completed tasks")
+
+	output = captureStdout(t, func() {
+		require.NoError(t, ArchiveCommand(taskFile, []string{"--older-than", "7d"}))
+	})
+	require.Contains(t, output, "Archived 1 completed task to")
+
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	require.Error(t, ArchiveCommand(taskFile, []string{"--older-than", "soon"}))
+}
+
+func TestListAllIncludesArchive(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	require.NoError(t, taskFile.AddTask(internal.NewTask("Open task")))
+	addArchivedTask(t, taskFile, "Archived task", time.Now().AddDate(0, -2, 0))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, ListCommandWithOptions(taskFile, ListOptions{}))
+	})
+	require.NotContains(t, output, "Archived task")
+
+	opts, err := parseListArgs(ListOptions{}, []string{"-a"})
+	require.NoError(t, err)
+	require.True(t, opts.ShowAll)
+	output = captureStdout(t, func() {
+		require.NoError(t, ListCommandWithOptions(taskFile, opts))
+	})
+	require.Contains(t, output, "Open task")
+	require.Contains(t, output, "Archived task")
+}
+
+func TestDailyReportReadsArchive(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	completedAt := time.Date(2024, 3, 12, 10, 0, 0, 0, time.Local)
+	addArchivedTask(t, taskFile, "Archived in March", completedAt)
+
+	r := chi.NewRouter()
+	NewController(taskFile).registerRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/daily/%d/%d", 2024, 3), nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "Archived in March")
+}
+
+func TestModifyRejectsArchivedTask(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	addArchivedTask(t, taskFile, "Archived task", time.Now().AddDate(0, -2, 0))
+
+	// `ls -a` lists the archived task, but it can't be changed
+	err := StatusCommand(taskFile, ListOptions{ShowAll: true}, internal.StatusTODO, []string{"1"})
+	require.ErrorContains(t, err, "task is archived")
+}
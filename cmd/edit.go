@@ -28,22 +28,19 @@ func EditCommand(taskFile *internal.TaskFile) error {
 		return nil
 	}
 
-	// Remember the original updated timestamp for conflict check
-	originalUpdated := task.Updated
+	// Remember the original version, to merge the edit with changes made meanwhile
+	original := *task
 
 	if err := editTaskNote(task); err != nil {
 		return fmt.Errorf("failed to edit task: %w", err)
 	}
 
-	if err := taskFile.UpdateTaskWithConflictCheck(task.ID, originalUpdated, func(t *internal.Task) {
+	if err := taskFile.UpdateTaskWithMerge(original, func(t *internal.Task) {
 		t.Title = task.Title
 		t.Projects = task.Projects
 		t.Note = task.Note
 	}); err != nil {
-		if strings.Contains(err.Error(), "modified by another process") {
-			return fmt.Errorf("conflict: task was modified by another process, please try again")
-		}
-		return fmt.Errorf("failed to save task: %w", err)
+		return saveError(err)
 	}
 
 	fmt.Printf("Task updated: %s\n", task.Title)
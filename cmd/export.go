@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"taskeru/internal"
+)
+
+// exportGroup is a heading of the Markdown and HTML exports with the tasks under it
+type exportGroup struct {
+	Name  string
+	Tasks []internal.Task
+}
+
+// ExportCommand writes tasks in formats for other applications:
+//
+//	taskeru export ics [filter]
+//	taskeru export --format md|html|csv|ics [--group project|status] [--title text] [@view] [-a] [filter]
+func ExportCommand(taskFile *internal.TaskFile, listOpts ListOptions, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: export --format md|html|csv|ics [--group project|status] [@view] [-a] [filter]")
+	}
+	if args[0] == "ics" {
+		return exportICSCommand(taskFile, listOpts, args[1:])
+	}
+
+	format, group, title := "", "project", "Tasks"
+	var listArgs []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(args[i], "--"), "=")
+		if !strings.HasPrefix(args[i], "--") || (name != "format" && name != "group" && name != "title") {
+			listArgs = append(listArgs, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return fmt.Errorf("missing value for --%s", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch name {
+		case "format":
+			format = value
+		case "group":
+			group = value
+		case "title":
+			title = value
+		}
+	}
+
+	if format == "" {
+		return fmt.Errorf("--format md|html|csv|ics is required (usage: export --format md|html|csv|ics [--group project|status] [@view] [-a] [filter])")
+	}
+	if group != "project" && group != "status" {
+		return fmt.Errorf("unknown group: %s (expected project or status)", group)
+	}
+
+	if format == "ics" {
+		return exportICSCommand(taskFile, listOpts, listArgs)
+	}
+
+	opts, tasks, err := loadExportTasks(taskFile, listOpts, listArgs)
+	if err != nil {
+		return err
+	}
+	if opts.ProjectFilter != "" && title == "Tasks" {
+		title = "Tasks +" + opts.ProjectFilter
+	}
+
+	switch format {
+	case "md", "markdown":
+		return writeTasksMarkdown(os.Stdout, title, groupTasksForExport(tasks, group))
+	case "html":
+		return writeTasksHTML(os.Stdout, title, groupTasksForExport(tasks, group))
+	case "csv":
+		return writeTasksCSV(os.Stdout, tasks)
+	default:
+		return fmt.Errorf("unknown export format: %s (expected md, html, csv or ics)", format)
+	}
+}
+
+// exportICSCommand writes the tasks with due or scheduled dates as iCalendar,
+// filtered like `ls`, so that `taskeru -p work export ics` is the calendar of a project
+func exportICSCommand(taskFile *internal.TaskFile, listOpts ListOptions, args []string) error {
+	opts, tasks, err := loadExportTasks(taskFile, listOpts, args)
+	if err != nil {
+		return err
+	}
+	return writeICS(os.Stdout, tasks, calendarName(opts.ProjectFilter))
+}
+
+// loadExportTasks returns the tasks `ls` would show for the arguments
+func loadExportTasks(taskFile *internal.TaskFile, listOpts ListOptions, args []string) (ListOptions, []internal.Task, error) {
+	opts, err := parseListArgs(listOpts, args)
+	if err != nil {
+		return opts, nil, err
+	}
+	if opts, err = resolveView(opts); err != nil {
+		return opts, nil, err
+	}
+	query, err := internal.ParseQuery(opts.Query)
+	if err != nil {
+		return opts, nil, err
+	}
+
+	tasks, err := loadListTasks(taskFile, opts)
+	if err != nil {
+		return opts, nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+	return opts, internal.FilterTasksByQuery(listedTasks(tasks, opts), query), nil
+}
+
+// calendarName names the iCalendar feed after the project it is filtered by
+func calendarName(project string) string {
+	if project == "" {
+		return "taskeru"
+	}
+	return "taskeru +" + project
+}
+
+// groupTasksForExport groups tasks by "project" or "status", keeping their order within each group.
+// Projects are sorted by name and a task with several projects is listed under each of them.
+// Statuses are in workflow order. Empty groups are left out.
+func groupTasksForExport(tasks []internal.Task, by string) []exportGroup {
+	tasksByName := make(map[string][]internal.Task)
+	var names []string
+	add := func(name string, task internal.Task) {
+		if _, ok := tasksByName[name]; !ok {
+			names = append(names, name)
+		}
+		tasksByName[name] = append(tasksByName[name], task)
+	}
+
+	if by == "status" {
+		for _, task := range tasks {
+			add(task.Status, task)
+		}
+		names = nil
+		for _, status := range internal.GetAllStatuses() {
+			if len(tasksByName[status]) > 0 {
+				names = append(names, status)
+			}
+		}
+	} else {
+		var noProject []internal.Task
+		for _, task := range tasks {
+			if len(task.Projects) == 0 {
+				noProject = append(noProject, task)
+			}
+			for _, project := range task.Projects {
+				add("+"+project, task)
+			}
+		}
+		sort.Strings(names)
+		if len(noProject) > 0 {
+			tasksByName["(no project)"] = noProject
+			names = append(names, "(no project)")
+		}
+	}
+
+	groups := make([]exportGroup, 0, len(names))
+	for _, name := range names {
+		groups = append(groups, exportGroup{Name: name, Tasks: tasksByName[name]})
+	}
+	return groups
+}
+
+// writeTasksMarkdown writes the groups as a Markdown document with a checklist per group.
+// Notes are indented under their task so that they stay part of the list item.
+func writeTasksMarkdown(out io.Writer, title string, groups []exportGroup) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title)
+	for _, group := range groups {
+		fmt.Fprintf(&b, "\n## %s (%d)\n\n", group.Name, len(group.Tasks))
+		for _, task := range group.Tasks {
+			check := " "
+			if task.IsCompleted() {
+				check = "x"
+			}
+			fmt.Fprintf(&b, "- [%s] %s", check, task.Title)
+			if task.Priority != "" {
+				fmt.Fprintf(&b, " `%s`", task.Priority)
+			}
+			if details := exportDetails(task); len(details) > 0 {
+				fmt.Fprintf(&b, " — %s", strings.Join(details, " · "))
+			}
+			b.WriteString("\n")
+
+			if note := strings.TrimSpace(task.Note); note != "" {
+				b.WriteString("\n")
+				for _, line := range strings.Split(note, "\n") {
+					if line == "" {
+						b.WriteString("\n")
+					} else {
+						b.WriteString("    " + line + "\n")
+					}
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// writeTasksHTML writes the groups as a standalone HTML document, with the notes rendered from Markdown
+func writeTasksHTML(out io.Writer, title string, groups []exportGroup) error {
+	data := struct {
+		Title  string
+		Groups []exportGroup
+	}{
+		Title:  title,
+		Groups: groups,
+	}
+	return templates.ExecuteTemplate(out, "export.html", data)
+}
+
+// exportDetails returns the status and dates shown next to a task in the exports
+func exportDetails(task internal.Task) []string {
+	var details []string
+	if task.Status != internal.StatusTODO && task.Status != internal.StatusDONE {
+		details = append(details, task.Status)
+	}
+	if task.CompletedAt != nil && task.IsCompleted() {
+		details = append(details, "completed "+task.CompletedAt.Format("2006-01-02"))
+	}
+	if task.DueDate != nil {
+		details = append(details, "due "+task.DueDate.Format("2006-01-02"))
+	}
+	if task.ScheduledDate != nil {
+		details = append(details, "scheduled "+task.ScheduledDate.Format("2006-01-02"))
+	}
+	return details
+}
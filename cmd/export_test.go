@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"taskeru/internal"
+)
+
+func TestGroupTasksForExport(t *testing.T) {
+	tasks := []internal.Task{
+		{ID: "1", Title: "Write report", Status: internal.StatusDOING, Projects: []string{"work", "writing"}},
+		{ID: "2", Title: "Buy milk", Status: internal.StatusTODO},
+		{ID: "3", Title: "Plan sprint", Status: internal.StatusDONE, Projects: []string{"work"}},
+	}
+
+	groups := groupTasksForExport(tasks, "project")
+	require.Len(t, groups, 3)
+	require.Equal(t, "+work", groups[0].Name)
+	require.Len(t, groups[0].Tasks, 2)
+	require.Equal(t, "+writing", groups[1].Name)
+	require.Equal(t, "(no project)", groups[2].Name)
+	require.Equal(t, "Buy milk", groups[2].Tasks[0].Title)
+
+	groups = groupTasksForExport(tasks, "status")
+	require.Len(t, groups, 3)
+	require.Equal(t, []string{internal.StatusTODO, internal.StatusDOING, internal.StatusDONE},
+		[]string{groups[0].Name, groups[1].Name, groups[2].Name})
+}
+
+func TestWriteTasksMarkdown(t *testing.T) {
+	completed := time.Date(2025, 3, 18, 12, 30, 0, 0, time.Local)
+	due := time.Date(2025, 3, 20, 23, 59, 59, 0, time.Local)
+	groups := []exportGroup{{Name: "+work", Tasks: []internal.Task{
+		{Title: "Write report", Status: internal.StatusDOING, Priority: "A", DueDate: &due, Note: "First line\n\n- item"},
+		{Title: "Plan sprint", Status: internal.StatusDONE, CompletedAt: &completed},
+	}}}
+
+	var b strings.Builder
+	require.NoError(t, writeTasksMarkdown(&b, "Tasks", groups))
+	output := b.String()
+
+	require.True(t, strings.HasPrefix(output, "# Tasks\n\n## +work (2)\n\n"))
+	require.Contains(t, output, "- [ ] Write report `A` — DOING · due 2025-03-20\n\n    First line\n\n    - item\n")
+	require.Contains(t, output, "- [x] Plan sprint — completed 2025-03-18\n")
+}
+
+func TestExportCommand(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	for _, title := range []string{"Write report +work", "Buy milk +home", "Plan sprint +work"} {
+		require.NoError(t, taskFile.AddTask(internal.ParseTask(title)))
+	}
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	plan := findTaskByTitle(t, tasks, "Plan sprint")
+	err = taskFile.UpdateTaskWithConflictCheck(plan.ID, plan.Updated, func(task *internal.Task) {
+		task.SetStatus(internal.StatusDONE)
+		task.Note = "See **the board**"
+	})
+	require.NoError(t, err)
+
+	output := captureStdout(t, func() {
+		require.NoError(t, ExportCommand(taskFile, ListOptions{ProjectFilter: "work"}, []string{"--format", "md"}))
+	})
+	require.Contains(t, output, "# Tasks +work\n")
+	require.Contains(t, output, "## +work (2)")
+	require.Contains(t, output, "- [x] Plan sprint — completed "+time.Now().Format("2006-01-02"))
+	require.NotContains(t, output, "Buy milk")
+
+	output = captureStdout(t, func() {
+		require.NoError(t, ExportCommand(taskFile, ListOptions{}, []string{"--format=html", "--group", "status", "--title", "Status <report>"}))
+	})
+	require.Contains(t, output, "<title>Status &lt;report&gt;</title>")
+	require.Contains(t, output, "<h2>DONE")
+	require.Contains(t, output, "<strong>the board</strong>")
+	require.Contains(t, output, "completed "+time.Now().Format("2006-01-02"))
+
+	output = captureStdout(t, func() {
+		require.NoError(t, ExportCommand(taskFile, ListOptions{}, []string{"--format", "csv", "+home"}))
+	})
+	require.True(t, strings.HasPrefix(output, strings.Join(taskColumns, ",")+"\n"))
+	require.Contains(t, output, "Buy milk")
+	require.NotContains(t, output, "Write report")
+
+	require.Error(t, ExportCommand(taskFile, ListOptions{}, []string{"--format", "pdf"}))
+	require.ErrorContains(t, ExportCommand(taskFile, ListOptions{}, []string{"@today"}), "--format md|html|csv|ics is required")
+	require.Error(t, ExportCommand(taskFile, ListOptions{}, []string{"--format", "md", "--group", "priority"}))
+	require.Error(t, ExportCommand(taskFile, ListOptions{}, []string{"--format", "md", "--unknown", "x"}))
+}
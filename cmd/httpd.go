@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
 	"fmt"
@@ -62,6 +63,19 @@ func init() {
 		"lower": func(s string) string {
 			return strings.ToLower(s)
 		},
+		"exportDetails": func(task internal.Task) string {
+			return strings.Join(exportDetails(task), " · ")
+		},
+		"etag": func(task internal.Task) string {
+			return taskETag(&task)
+		},
+		"priorities": func() []string {
+			priorities := []string{""}
+			for c := 'A'; c <= 'Z'; c++ {
+				priorities = append(priorities, string(c))
+			}
+			return priorities
+		},
 		"formatDateWithWeekday": func(dateStr string) string {
 			// Parse the date string (format: YYYY-MM-DD)
 			t, err := time.Parse("2006-01-02", dateStr)
@@ -91,14 +105,14 @@ func HttpdCommand(taskFile *internal.TaskFile, addr string) error {
 	r.Use(middleware.Recoverer)
 
 	controller := NewController(taskFile)
+	controller.addr = addr
+	controller.registerRoutes(r)
+
+	// Push changes of the task file to the browsers
+	go controller.watchTaskFile(context.Background(), taskFileWatchInterval)
 
-	// Routes
-	r.Get("/", controller.kanbanHandler)
-	r.Get("/kanban", controller.kanbanHandler)
-	r.Get("/daily", controller.dailyReportHandler)
-	r.Get("/daily/{year}/{month}", controller.dailyReportHandler)
-	r.Get("/api/tasks", controller.apiTasksHandler)
-	r.Get("/static/style.css", controller.styleHandler)
+	// Archiving runs before every command, but the server keeps running
+	go autoArchivePeriodically(context.Background(), taskFile, autoArchiveInterval)
 
 	fmt.Printf("Starting HTTP server on http://%s\n", addr)
 	fmt.Println("Press Ctrl+C to stop")
@@ -106,15 +120,49 @@ func HttpdCommand(taskFile *internal.TaskFile, addr string) error {
 	return http.ListenAndServe(addr, r)
 }
 
+func (c *Controller) registerRoutes(r chi.Router) {
+	r.Get("/", c.kanbanHandler)
+	r.Get("/kanban", c.kanbanHandler)
+	r.Get("/daily", c.dailyReportHandler)
+	r.Get("/daily/{year}/{month}", c.dailyReportHandler)
+	r.Get("/report/weekly", c.weeklyReportHandler)
+	r.Get("/calendar", c.calendarHandler)
+	r.Get("/calendar.ics", c.calendarFeedHandler)
+	r.Get("/calendar/{year}/{month}", c.calendarHandler)
+	r.Get("/static/style.css", c.styleHandler)
+	r.Get("/events", c.eventsHandler)
+
+	// REST API
+	r.Group(func(r chi.Router) {
+		r.Use(c.checkAPIRequest)
+		r.Get("/api/tasks", c.apiTasksHandler)
+		r.Post("/api/tasks", c.apiCreateTaskHandler)
+		r.Post("/api/tasks/bulk", c.apiBulkHandler)
+		r.Get("/api/tasks/{id}", c.apiGetTaskHandler)
+		r.Patch("/api/tasks/{id}", c.apiPatchTaskHandler)
+		r.Delete("/api/tasks/{id}", c.apiDeleteTaskHandler)
+		r.Post("/api/tasks/{id}/status", c.apiTaskStatusHandler)
+		r.Get("/api/tasks/{id}/history", c.apiTaskHistoryHandler)
+	})
+}
+
 type Controller struct {
 	taskFile *internal.TaskFile
+	events   *eventBroker
+	addr     string // Address the server listens on, whose host name the API accepts besides localhost
 }
 
 func NewController(taskFile *internal.TaskFile) *Controller {
-	return &Controller{taskFile: taskFile}
+	return &Controller{taskFile: taskFile, events: newEventBroker()}
 }
 
 func (c *Controller) kanbanHandler(w http.ResponseWriter, r *http.Request) {
+	query, err := internal.ParseQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	tasks, err := c.taskFile.LoadTasks()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -123,18 +171,33 @@ func (c *Controller) kanbanHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Sort and group tasks by status
 	internal.SortTasks(tasks)
-	tasksByStatus := groupTasksByStatus(tasks)
+	tasksByStatus := groupTasksByStatus(internal.FilterTasksByQuery(tasks, query))
+	statuses := []string{"TODO", "DOING", "WAITING", "DONE", "WONTDO"}
+
+	// Remaining estimate and points per column, e.g. "est:5h pts:8"
+	effort := make(map[string]string, len(statuses))
+	for _, status := range statuses {
+		effort[status] = internal.SumEffort(tasksByStatus[status]).String()
+	}
 
 	data := struct {
 		Title         string
 		TasksByStatus map[string][]internal.Task
+		Subtasks      map[string]internal.SubtaskProgress
+		Blockers      map[string]int
+		Effort        map[string]string
 		Statuses      []string
 		ActiveView    string
+		Query         string
 	}{
 		Title:         "Taskeru - Kanban View",
 		TasksByStatus: tasksByStatus,
-		Statuses:      []string{"TODO", "DOING", "WAITING", "DONE", "WONTDO"},
+		Subtasks:      internal.GetSubtaskProgress(tasks),
+		Blockers:      internal.CountOpenBlockers(tasks),
+		Effort:        effort,
+		Statuses:      statuses,
 		ActiveView:    "kanban",
+		Query:         r.URL.Query().Get("q"),
 	}
 
 	if err := templates.ExecuteTemplate(w, "kanban_page.html", data); err != nil {
@@ -159,7 +222,8 @@ func (c *Controller) dailyReportHandler(w http.ResponseWriter, r *http.Request)
 
 	targetDate := time.Date(targetYear, time.Month(targetMonth), 1, 0, 0, 0, 0, time.Local)
 
-	tasks, err := c.taskFile.LoadTasks()
+	// Past months are mostly in the archive
+	tasks, err := c.taskFile.LoadTasksWithArchive()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -177,6 +241,7 @@ func (c *Controller) dailyReportHandler(w http.ResponseWriter, r *http.Request)
 		Month           int
 		MonthName       string
 		TasksByDate     map[string][]internal.Task
+		TimeByDate      map[string]string
 		Dates           []string
 		AvailableMonths []YearMonth
 		ActiveView      string
@@ -188,6 +253,7 @@ func (c *Controller) dailyReportHandler(w http.ResponseWriter, r *http.Request)
 		Month:           targetMonth,
 		MonthName:       targetDate.Month().String(),
 		TasksByDate:     tasksByDate,
+		TimeByDate:      trackedTimeByDate(tasks, targetDate),
 		Dates:           getSortedDates(tasksByDate),
 		AvailableMonths: availableMonths,
 		ActiveView:      "daily",
@@ -201,12 +267,25 @@ func (c *Controller) dailyReportHandler(w http.ResponseWriter, r *http.Request)
 }
 
 func (c *Controller) apiTasksHandler(w http.ResponseWriter, r *http.Request) {
+	query, err := internal.ParseQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	tasks, err := c.taskFile.LoadTasks()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if !query.IsEmpty() {
+		tasks = internal.FilterTasksByQuery(tasks, query)
+		if tasks == nil {
+			tasks = []internal.Task{}
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(tasks)
 }
@@ -259,24 +338,40 @@ func groupTasksByDate(tasks []internal.Task, targetMonth time.Time) map[string][
 
 		// Also include completed tasks in the target month
 		if task.CompletedAt != nil && task.CompletedAt.After(startOfMonth) && task.CompletedAt.Before(endOfMonth) {
-			dateKey := task.CompletedAt.Format("2006-01-02")
-			// Avoid duplicates
-			found := false
-			for _, t := range result[dateKey] {
-				if t.ID == task.ID {
-					found = true
-					break
-				}
-			}
-			if !found {
-				result[dateKey] = append(result[dateKey], task)
-			}
+			addTaskToDate(result, task.CompletedAt.Format("2006-01-02"), task)
+		}
+
+		// And the days time was tracked on the task
+		for dateKey := range internal.TrackedTimeByDay([]internal.Task{task}, startOfMonth, endOfMonth) {
+			addTaskToDate(result, dateKey, task)
 		}
 	}
 
 	return result
 }
 
+// addTaskToDate adds the task to the tasks of the date, unless it is already there
+func addTaskToDate(tasksByDate map[string][]internal.Task, dateKey string, task internal.Task) {
+	for _, t := range tasksByDate[dateKey] {
+		if t.ID == task.ID {
+			return
+		}
+	}
+	tasksByDate[dateKey] = append(tasksByDate[dateKey], task)
+}
+
+// trackedTimeByDate formats the time tracked on each day of the month
+func trackedTimeByDate(tasks []internal.Task, targetMonth time.Time) map[string]string {
+	startOfMonth := time.Date(targetMonth.Year(), targetMonth.Month(), 1, 0, 0, 0, 0, time.Local)
+	result := make(map[string]string)
+	for dateKey, d := range internal.TrackedTimeByDay(tasks, startOfMonth, startOfMonth.AddDate(0, 1, 0)) {
+		if d >= time.Minute {
+			result[dateKey] = internal.FormatDuration(d)
+		}
+	}
+	return result
+}
+
 func getSortedDates(tasksByDate map[string][]internal.Task) []string {
 	dates := make([]string, 0, len(tasksByDate))
 	for date := range tasksByDate {
@@ -436,6 +531,36 @@ body {
 	padding: 0 1rem;
 }
 
+/* Search */
+.search-form {
+	display: flex;
+	gap: 0.5rem;
+	align-items: center;
+}
+
+.search-form input {
+	flex: 1;
+	padding: 0.5rem;
+	border: 1px solid var(--border-color);
+	border-radius: 4px;
+	background: var(--bg-primary);
+	color: var(--text-primary);
+	font-family: monospace;
+}
+
+.search-form button {
+	padding: 0.5rem 1rem;
+	border: none;
+	border-radius: 4px;
+	background: #007bff;
+	color: white;
+	cursor: pointer;
+}
+
+.search-form a {
+	color: var(--text-secondary);
+}
+
 /* Kanban Board */
 .kanban-board {
 	display: grid;
@@ -466,6 +591,17 @@ body {
 	border-radius: 4px;
 }
 
+.kanban-effort {
+	display: block;
+	font-size: 0.75rem;
+	font-weight: normal;
+	color: var(--text-secondary);
+}
+
+.kanban-effort:empty {
+	display: none;
+}
+
 .kanban-cards {
 	display: flex;
 	flex-direction: column;
@@ -494,6 +630,114 @@ body {
 	box-shadow: 0 2px 6px rgba(0,0,0,0.15);
 }
 
+.kanban-card[draggable="true"] {
+	cursor: grab;
+}
+
+.kanban-card.dragging {
+	opacity: 0.5;
+}
+
+.kanban-cards.drag-over {
+	outline: 2px dashed #007bff;
+	outline-offset: 2px;
+	border-radius: 4px;
+}
+
+.kanban-card.stale {
+	border-left: 4px solid #dc3545;
+}
+
+.card-error {
+	margin-top: 0.5rem;
+	padding: 0.4rem 0.5rem;
+	background: #f8d7da;
+	color: #721c24;
+	border-radius: 3px;
+	font-size: 0.8rem;
+}
+
+.card-error a {
+	color: inherit;
+	font-weight: bold;
+}
+
+.card-error button {
+	border: 1px solid currentColor;
+	border-radius: 3px;
+	background: none;
+	color: inherit;
+	font-size: inherit;
+	font-weight: bold;
+	cursor: pointer;
+}
+
+.card-edit-button {
+	float: right;
+	border: none;
+	background: none;
+	color: var(--text-secondary);
+	cursor: pointer;
+	font-size: 0.85rem;
+}
+
+.card-edit {
+	display: flex;
+	flex-direction: column;
+	gap: 0.4rem;
+	margin-top: 0.5rem;
+}
+
+.card-edit input,
+.card-edit select,
+.card-edit textarea,
+.quick-add input {
+	padding: 0.35rem;
+	border: 1px solid var(--border-color);
+	border-radius: 3px;
+	font-family: inherit;
+	font-size: 0.85rem;
+}
+
+.card-edit textarea {
+	min-height: 5rem;
+	font-family: monospace;
+}
+
+.card-edit-actions {
+	display: flex;
+	gap: 0.4rem;
+	justify-content: flex-end;
+}
+
+.card-history {
+	margin: 0.5rem 0 0;
+	padding: 0.4rem 0.5rem 0.4rem 1.5rem;
+	max-height: 12rem;
+	overflow-y: auto;
+	background: var(--bg-secondary);
+	border-radius: 3px;
+	font-size: 0.75rem;
+	overflow-wrap: anywhere;
+}
+
+.card-history li + li {
+	margin-top: 0.3rem;
+}
+
+.history-meta {
+	color: var(--text-secondary);
+}
+
+.quick-add {
+	display: flex;
+	margin-bottom: 0.75rem;
+}
+
+.quick-add input {
+	flex: 1;
+}
+
 .card-priority {
 	display: inline-block;
 	padding: 0.2rem 0.4rem;
@@ -535,11 +779,27 @@ body {
 	color: #f57c00;
 }
 
+.date-badge.recurrence {
+	background: #f3e5f5;
+	color: #7b1fa2;
+}
+
+.date-badge.blocked {
+	background: #fce4ec;
+	color: #c2185b;
+}
+
 .date-badge.completed {
 	background: #e8f5e9;
 	color: #2e7d32;
 }
 
+.card-effort,
+.card-subtasks {
+	font-size: 0.8rem;
+	color: var(--text-secondary);
+}
+
 .card-projects {
 	display: flex;
 	flex-wrap: wrap;
@@ -615,6 +875,118 @@ body {
 	color: var(--text-primary);
 }
 
+/* Weekly report */
+.review-count {
+	font-weight: normal;
+	color: var(--text-secondary);
+}
+
+.review-detail {
+	font-size: 0.85rem;
+	color: var(--text-secondary);
+}
+
+.review-none {
+	color: var(--text-secondary);
+}
+
+/* Calendar */
+.calendar {
+	display: grid;
+	grid-template-columns: repeat(7, minmax(0, 1fr));
+	gap: 1px;
+	background: var(--border-color);
+	border: 1px solid var(--border-color);
+	border-radius: 8px;
+	overflow: hidden;
+}
+
+.calendar-weekday {
+	padding: 0.5rem;
+	text-align: center;
+	font-weight: bold;
+	background: var(--bg-primary);
+}
+
+.calendar-day {
+	min-height: 110px;
+	padding: 0.25rem;
+	background: var(--bg-primary);
+}
+
+.calendar-day.weekend {
+	background: #fafafa;
+}
+
+.calendar-day.other-month {
+	background: var(--bg-secondary);
+	color: var(--text-secondary);
+}
+
+.calendar-day.today .calendar-day-number {
+	display: inline-block;
+	min-width: 1.6rem;
+	background: #007bff;
+	color: white;
+	border-radius: 12px;
+	text-align: center;
+}
+
+.calendar-day.drag-over {
+	outline: 2px dashed #007bff;
+	outline-offset: -2px;
+}
+
+.calendar-day-number {
+	font-size: 0.85rem;
+	font-weight: bold;
+}
+
+.calendar-entries {
+	display: flex;
+	flex-direction: column;
+	gap: 0.2rem;
+}
+
+.calendar-entry {
+	padding: 0.15rem 0.3rem;
+	border-radius: 3px;
+	font-size: 0.8rem;
+	background: var(--kanban-waiting);
+	cursor: move;
+	overflow: hidden;
+	text-overflow: ellipsis;
+	white-space: nowrap;
+}
+
+.calendar-entry.scheduled {
+	background: var(--kanban-todo);
+	border: 1px solid var(--border-color);
+}
+
+.calendar-entry.done {
+	opacity: 0.6;
+	text-decoration: line-through;
+}
+
+.calendar-entry.overdue {
+	background: var(--kanban-wontdo);
+	color: #721c24;
+	font-weight: bold;
+}
+
+.calendar-entry.dragging {
+	opacity: 0.5;
+}
+
+.calendar-entry.stale {
+	border-left: 4px solid #dc3545;
+}
+
+.calendar-entry .card-error {
+	white-space: normal;
+}
+
 .task-note h1, .task-note h2, .task-note h3 {
 	margin-top: 1rem;
 	margin-bottom: 0.5rem;
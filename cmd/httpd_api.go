@@ -0,0 +1,616 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"taskeru/internal"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxAPIRequestBody limits the size of JSON request bodies
+const maxAPIRequestBody = 1 << 20
+
+// errAPIConflict is returned when If-Match doesn't match the current version of a task
+var errAPIConflict = errors.New("task has been modified by another process")
+
+// createTaskRequest is the body of POST /api/tasks.
+// The title is parsed like `taskeru add`, so it may contain +project, due:, repeat: etc.
+type createTaskRequest struct {
+	Title    string `json:"title"`
+	Note     string `json:"note,omitempty"`
+	Priority string `json:"priority,omitempty"`
+	Status   string `json:"status,omitempty"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+// patchTaskRequest is the body of PATCH /api/tasks/{id}. Omitted fields are left unchanged.
+type patchTaskRequest struct {
+	Title         *string   `json:"title,omitempty"`
+	Note          *string   `json:"note,omitempty"`
+	Priority      *string   `json:"priority,omitempty"`
+	Status        *string   `json:"status,omitempty"`
+	Projects      *[]string `json:"projects,omitempty"`
+	DueDate       *string   `json:"due_date,omitempty"`       // Date as accepted by due:, or "" to clear
+	ScheduledDate *string   `json:"scheduled_date,omitempty"` // Date as accepted by sched:, or "" to clear
+	ParentID      *string   `json:"parent_id,omitempty"`
+
+	// Base holds the values of the patched fields the client started from. With it, a stale If-Match
+	// doesn't fail: the patch is merged with the changes made since, unless they changed the same fields.
+	Base *patchTaskRequest `json:"base,omitempty"`
+}
+
+// fields returns the JSON names of the fields set in the request
+func (req *patchTaskRequest) fields() []string {
+	var fields []string
+	for name, set := range map[string]bool{
+		"title":          req.Title != nil,
+		"note":           req.Note != nil,
+		"priority":       req.Priority != nil,
+		"status":         req.Status != nil,
+		"projects":       req.Projects != nil,
+		"due_date":       req.DueDate != nil,
+		"scheduled_date": req.ScheduledDate != nil,
+		"parent_id":      req.ParentID != nil,
+	} {
+		if set {
+			fields = append(fields, name)
+		}
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+// bulkOperation is one entry of POST /api/tasks/bulk
+type bulkOperation struct {
+	Op     string             `json:"op"` // create, update, status or delete
+	ID     string             `json:"id,omitempty"`
+	ETag   string             `json:"etag,omitempty"` // Same as the If-Match header of single requests
+	Task   *createTaskRequest `json:"task,omitempty"`
+	Patch  *patchTaskRequest  `json:"patch,omitempty"`
+	Status string             `json:"status,omitempty"`
+}
+
+// bulkResult is the outcome of one bulk operation, with the HTTP status the single request would return
+type bulkResult struct {
+	Status int            `json:"status"`
+	Task   *internal.Task `json:"task,omitempty"`
+	Error  string         `json:"error,omitempty"`
+	Fields []string       `json:"fields,omitempty"` // Conflicting fields of a merged update
+}
+
+// apiError carries the HTTP status for a failed API operation
+type apiError struct {
+	status int
+	err    error
+	task   *internal.Task // Current version of the task for conflicts
+	fields []string       // Fields changed both by the request and by someone else
+}
+
+func (e *apiError) Error() string {
+	return e.err.Error()
+}
+
+func newAPIError(status int, format string, args ...any) *apiError {
+	return &apiError{status: status, err: fmt.Errorf(format, args...)}
+}
+
+// taskETag returns the ETag of a task, derived from its Updated timestamp
+func taskETag(task *internal.Task) string {
+	return strconv.Quote(strconv.FormatInt(task.Updated.UnixNano(), 10))
+}
+
+// checkETag compares an If-Match value with the current version of a task. An empty value always matches.
+func checkETag(etag string, task *internal.Task) error {
+	etag = strings.TrimSpace(etag)
+	if etag == "" || etag == "*" {
+		return nil
+	}
+	if strings.TrimPrefix(etag, "W/") != taskETag(task) {
+		return &apiError{status: http.StatusConflict, err: errAPIConflict, task: task}
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeTaskJSON(w http.ResponseWriter, status int, task *internal.Task) {
+	w.Header().Set("ETag", taskETag(task))
+	writeJSON(w, status, task)
+}
+
+// writeAPIError writes err as {"error": "..."}. Conflicts also include the current task.
+func writeAPIError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	body := map[string]any{"error": err.Error()}
+
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		status = apiErr.status
+		if apiErr.task != nil {
+			w.Header().Set("ETag", taskETag(apiErr.task))
+			body["task"] = apiErr.task
+		}
+		if apiErr.fields != nil {
+			body["fields"] = apiErr.fields
+		}
+	}
+	writeJSON(w, status, body)
+}
+
+// checkAPIRequest protects the API from other web pages. Browsers send simple cross-site requests,
+// e.g. a text/plain POST, without asking the server first, and DNS rebinding makes another site
+// look like the same origin. So changes need a JSON body, and must come from the server's own host.
+func (c *Controller) checkAPIRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !c.isOwnHost(r.Host) {
+			writeAPIError(w, newAPIError(http.StatusForbidden, "requests for host %s are not allowed", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+				writeAPIError(w, newAPIError(http.StatusForbidden, "cross-origin requests are not allowed"))
+				return
+			}
+		}
+		// A DELETE has no body to check
+		if r.Method != http.MethodDelete || r.ContentLength != 0 {
+			if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+				writeAPIError(w, newAPIError(http.StatusUnsupportedMediaType, "Content-Type must be application/json"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isOwnHost reports whether a Host header names this server: localhost, an IP address,
+// which DNS rebinding can't produce, or the host name the server listens on
+func (c *Controller) isOwnHost(host string) bool {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	hostname = strings.Trim(hostname, "[]")
+	if hostname == "localhost" || net.ParseIP(hostname) != nil {
+		return true
+	}
+	listenHost, _, err := net.SplitHostPort(c.addr)
+	return err == nil && listenHost != "" && strings.EqualFold(listenHost, hostname)
+}
+
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIRequestBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return newAPIError(http.StatusBadRequest, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// findTask loads the task referenced by a full ID or unique ID prefix
+func (c *Controller) findTask(ref string) (*internal.Task, error) {
+	tasks, err := c.taskFile.LoadTasks()
+	if err != nil {
+		return nil, err
+	}
+	id, err := internal.ResolveTaskID(tasks, ref)
+	if err != nil {
+		return nil, newAPIError(http.StatusNotFound, "%v", err)
+	}
+	for i := range tasks {
+		if tasks[i].ID == id {
+			return &tasks[i], nil
+		}
+	}
+	return nil, newAPIError(http.StatusNotFound, "task not found: %s", ref)
+}
+
+func validateStatus(status string) error {
+	if !slices.Contains(internal.GetAllStatuses(), status) {
+		return newAPIError(http.StatusBadRequest, "invalid status: %s (expected %s)",
+			status, strings.Join(internal.GetAllStatuses(), ", "))
+	}
+	return nil
+}
+
+func validatePriority(priority string) error {
+	if priority != "" && (len(priority) != 1 || priority[0] < 'A' || priority[0] > 'Z') {
+		return newAPIError(http.StatusBadRequest, "invalid priority: %s (expected A-Z or empty)", priority)
+	}
+	return nil
+}
+
+// createTask adds a task built from the request and returns it as stored
+func (c *Controller) createTask(req *createTaskRequest) (*internal.Task, error) {
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, newAPIError(http.StatusBadRequest, "title is required")
+	}
+
+	task := internal.ParseTask(req.Title)
+	task.Note = req.Note
+	if req.Priority != "" {
+		priority := strings.ToUpper(req.Priority)
+		if err := validatePriority(priority); err != nil {
+			return nil, err
+		}
+		task.SetPriority(priority)
+	}
+	if req.Status != "" {
+		status := strings.ToUpper(req.Status)
+		if err := validateStatus(status); err != nil {
+			return nil, err
+		}
+		task.SetStatus(status)
+	}
+	if req.ParentID != "" {
+		parent, err := c.findTask(req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		task.ParentID = parent.ID
+	}
+
+	if err := c.taskFile.AddTask(task); err != nil {
+		return nil, newAPIError(http.StatusBadRequest, "failed to create task: %v", err)
+	}
+	// Reload, as storage resolves dependencies of the new task
+	return c.findTask(task.ID)
+}
+
+// buildPatch validates the request and returns the function applying it
+func (c *Controller) buildPatch(req *patchTaskRequest) (func(*internal.Task), error) {
+	var steps []func(*internal.Task)
+
+	if req.Title != nil {
+		if strings.TrimSpace(*req.Title) == "" {
+			return nil, newAPIError(http.StatusBadRequest, "title must not be empty")
+		}
+		// The new title may contain the same tokens as `taskeru add`
+		parsed := internal.ParseTask(*req.Title)
+		steps = append(steps, func(t *internal.Task) {
+			t.Title = parsed.Title
+			for _, project := range parsed.Projects {
+				if !slices.Contains(t.Projects, project) {
+					t.Projects = append(t.Projects, project)
+				}
+			}
+			if parsed.DueDate != nil {
+				t.DueDate = parsed.DueDate
+			}
+			if parsed.ScheduledDate != nil {
+				t.ScheduledDate = parsed.ScheduledDate
+			}
+			if parsed.Recurrence != "" {
+				t.Recurrence = parsed.Recurrence
+			}
+			if parsed.EstimateMinutes > 0 {
+				t.EstimateMinutes = parsed.EstimateMinutes
+			}
+			if parsed.Points > 0 {
+				t.Points = parsed.Points
+			}
+			t.BlockedBy = append(t.BlockedBy, parsed.BlockedBy...)
+			t.Blocks = parsed.Blocks
+			t.TypedTitle = parsed.TypedTitle
+		})
+	}
+	if req.Note != nil {
+		note := *req.Note
+		steps = append(steps, func(t *internal.Task) { t.Note = note })
+	}
+	if req.Priority != nil {
+		priority := strings.ToUpper(*req.Priority)
+		if err := validatePriority(priority); err != nil {
+			return nil, err
+		}
+		steps = append(steps, func(t *internal.Task) { t.SetPriority(priority) })
+	}
+	if req.Status != nil {
+		status := strings.ToUpper(*req.Status)
+		if err := validateStatus(status); err != nil {
+			return nil, err
+		}
+		steps = append(steps, func(t *internal.Task) { t.SetStatus(status) })
+	}
+	if req.Projects != nil {
+		var projects []string
+		for _, project := range *req.Projects {
+			projects = append(projects, strings.TrimPrefix(project, "+"))
+		}
+		steps = append(steps, func(t *internal.Task) { t.Projects = projects })
+	}
+	if req.DueDate != nil {
+		date, err := parseDateArg(*req.DueDate, false)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "%v", err)
+		}
+		steps = append(steps, func(t *internal.Task) { t.DueDate = date })
+	}
+	if req.ScheduledDate != nil {
+		date, err := parseDateArg(*req.ScheduledDate, true)
+		if err != nil {
+			return nil, newAPIError(http.StatusBadRequest, "%v", err)
+		}
+		steps = append(steps, func(t *internal.Task) { t.ScheduledDate = date })
+	}
+	if req.ParentID != nil {
+		parentID := ""
+		if *req.ParentID != "" {
+			parent, err := c.findTask(*req.ParentID)
+			if err != nil {
+				return nil, err
+			}
+			parentID = parent.ID
+		}
+		steps = append(steps, func(t *internal.Task) { t.ParentID = parentID })
+	}
+
+	return func(t *internal.Task) {
+		for _, step := range steps {
+			step(t)
+		}
+	}, nil
+}
+
+// updateTask applies updateFunc if etag matches the current version of the task.
+// If it doesn't, but base is given, base turns the current version back into the one the client
+// started from, and the change is merged with the changes made since (see UpdateTaskWithMerge).
+func (c *Controller) updateTask(ref string, etag string, base, updateFunc func(*internal.Task)) (*internal.Task, error) {
+	task, err := c.findTask(ref)
+	if err != nil {
+		return nil, err
+	}
+	original := *task
+	strict := false
+	if err := checkETag(etag, task); err != nil {
+		if base == nil {
+			return nil, err
+		}
+		base(&original)
+		// Any time but the current one makes it a merge
+		original.Updated = time.Time{}
+	} else if etag = strings.TrimSpace(etag); base == nil && etag != "" && etag != "*" {
+		// The task may change between the check above and the update, which checks again under the lock
+		strict = true
+	}
+
+	update := func() error { return c.taskFile.UpdateTaskWithMerge(original, updateFunc) }
+	if strict {
+		update = func() error { return c.taskFile.UpdateTaskWithConflictCheck(task.ID, task.Updated, updateFunc) }
+	}
+	if err := update(); err != nil {
+		var conflict *internal.ConflictError
+		if errors.As(err, &conflict) {
+			return nil, &apiError{status: http.StatusConflict, err: err, task: &conflict.Current, fields: conflict.Fields}
+		}
+		if errors.Is(err, internal.ErrTaskModified) {
+			if current, findErr := c.findTask(task.ID); findErr == nil {
+				return nil, &apiError{status: http.StatusConflict, err: errAPIConflict, task: current}
+			}
+		}
+		return nil, newAPIError(http.StatusBadRequest, "failed to update task: %v", err)
+	}
+	return c.findTask(task.ID)
+}
+
+// patchTask applies a PATCH request, merging it with changes made since req.Base if given
+func (c *Controller) patchTask(ref string, etag string, req *patchTaskRequest) (*internal.Task, error) {
+	updateFunc, err := c.buildPatch(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Base == nil {
+		return c.updateTask(ref, etag, nil, updateFunc)
+	}
+
+	if req.Base.Base != nil || !slices.Equal(req.fields(), req.Base.fields()) {
+		return nil, newAPIError(http.StatusBadRequest, "base must have the same fields as the patch")
+	}
+	base, err := c.buildPatch(req.Base)
+	if err != nil {
+		return nil, err
+	}
+	return c.updateTask(ref, etag, base, updateFunc)
+}
+
+// deleteTask moves the task to the trash if etag matches the current version of the task
+func (c *Controller) deleteTask(ref string, etag string) error {
+	task, err := c.findTask(ref)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(etag) == "" || strings.TrimSpace(etag) == "*" {
+		return c.taskFile.DeleteTask(task.ID)
+	}
+	if err := checkETag(etag, task); err != nil {
+		return err
+	}
+
+	// The task may change between the check above and the delete, which checks again under the lock
+	if err := c.taskFile.DeleteTaskWithConflictCheck(task.ID, task.Updated); err != nil {
+		if errors.Is(err, internal.ErrTaskModified) {
+			if current, findErr := c.findTask(task.ID); findErr == nil {
+				return &apiError{status: http.StatusConflict, err: errAPIConflict, task: current}
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *Controller) apiCreateTaskHandler(w http.ResponseWriter, r *http.Request) {
+	var req createTaskRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	task, err := c.createTask(&req)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/tasks/"+task.ID)
+	writeTaskJSON(w, http.StatusCreated, task)
+}
+
+func (c *Controller) apiGetTaskHandler(w http.ResponseWriter, r *http.Request) {
+	task, err := c.findTask(chi.URLParam(r, "id"))
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	writeTaskJSON(w, http.StatusOK, task)
+}
+
+func (c *Controller) apiPatchTaskHandler(w http.ResponseWriter, r *http.Request) {
+	var req patchTaskRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	task, err := c.patchTask(chi.URLParam(r, "id"), r.Header.Get("If-Match"), &req)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	writeTaskJSON(w, http.StatusOK, task)
+}
+
+func (c *Controller) apiTaskStatusHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	status := strings.ToUpper(req.Status)
+	if err := validateStatus(status); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	task, err := c.updateTask(chi.URLParam(r, "id"), r.Header.Get("If-Match"), nil, func(t *internal.Task) {
+		t.SetStatus(status)
+	})
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	writeTaskJSON(w, http.StatusOK, task)
+}
+
+func (c *Controller) apiDeleteTaskHandler(w http.ResponseWriter, r *http.Request) {
+	if err := c.deleteTask(chi.URLParam(r, "id"), r.Header.Get("If-Match")); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// historyEntry is a change journal entry of a task, with the change described for display
+type historyEntry struct {
+	internal.JournalEntry
+	Change string `json:"change"` // e.g. "priority: (none) → A"
+}
+
+// apiTaskHistoryHandler returns the recorded changes of a task, oldest first
+func (c *Controller) apiTaskHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	task, err := c.findTask(chi.URLParam(r, "id"))
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	entries, err := c.taskFile.LoadJournal(task.ID)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	history := make([]historyEntry, 0, len(entries))
+	for _, entry := range entries {
+		history = append(history, historyEntry{JournalEntry: entry, Change: describeJournalEntry(entry)})
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+// apiBulkHandler runs several operations in order. Each operation succeeds or fails on its own,
+// and the response lists the results in the same order.
+func (c *Controller) apiBulkHandler(w http.ResponseWriter, r *http.Request) {
+	var ops []bulkOperation
+	if err := decodeJSONBody(w, r, &ops); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	results := make([]bulkResult, 0, len(ops))
+	for _, op := range ops {
+		task, status, err := c.runBulkOperation(&op)
+		result := bulkResult{Status: status, Task: task}
+		if err != nil {
+			result.Status = http.StatusInternalServerError
+			var apiErr *apiError
+			if errors.As(err, &apiErr) {
+				result.Status = apiErr.status
+				result.Task = apiErr.task
+				result.Fields = apiErr.fields
+			}
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (c *Controller) runBulkOperation(op *bulkOperation) (*internal.Task, int, error) {
+	switch op.Op {
+	case "create":
+		if op.Task == nil {
+			return nil, 0, newAPIError(http.StatusBadRequest, "task is required for create")
+		}
+		task, err := c.createTask(op.Task)
+		return task, http.StatusCreated, err
+	case "update":
+		if op.Patch == nil {
+			return nil, 0, newAPIError(http.StatusBadRequest, "patch is required for update")
+		}
+		task, err := c.patchTask(op.ID, op.ETag, op.Patch)
+		return task, http.StatusOK, err
+	case "status":
+		status := strings.ToUpper(op.Status)
+		if err := validateStatus(status); err != nil {
+			return nil, 0, err
+		}
+		task, err := c.updateTask(op.ID, op.ETag, nil, func(t *internal.Task) {
+			t.SetStatus(status)
+		})
+		return task, http.StatusOK, err
+	case "delete":
+		return nil, http.StatusNoContent, c.deleteTask(op.ID, op.ETag)
+	default:
+		return nil, 0, newAPIError(http.StatusBadRequest, "unknown op: %q (expected create, update, status or delete)", op.Op)
+	}
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"taskeru/internal"
+)
+
+func newAPITestServer(t *testing.T) (*internal.TaskFile, http.Handler) {
+	t.Helper()
+	taskFile := internal.NewTaskFileForTesting(t)
+	r := chi.NewRouter()
+	NewController(taskFile).registerRoutes(r)
+	return taskFile, r
+}
+
+func doAPIRequest(t *testing.T, handler http.Handler, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Host = "127.0.0.1:7676"
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeAPITask(t *testing.T, rec *httptest.ResponseRecorder) internal.Task {
+	t.Helper()
+	var task internal.Task
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &task))
+	return task
+}
+
+func TestAPICreateAndGetTask(t *testing.T) {
+	_, handler := newAPITestServer(t)
+
+	rec := doAPIRequest(t, handler, http.MethodPost, "/api/tasks",
+		`{"title": "Write report +work due:tomorrow", "priority": "a", "note": "details"}`, nil)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	created := decodeAPITask(t, rec)
+	require.Equal(t, "Write report", created.Title)
+	require.Equal(t, []string{"work"}, created.Projects)
+	require.Equal(t, "A", created.Priority)
+	require.Equal(t, "details", created.Note)
+	require.NotNil(t, created.DueDate)
+	require.Equal(t, "/api/tasks/"+created.ID, rec.Header().Get("Location"))
+	require.Equal(t, taskETag(&created), rec.Header().Get("ETag"))
+
+	// Tasks can be addressed by ID prefix
+	rec = doAPIRequest(t, handler, http.MethodGet, "/api/tasks/"+created.ID[:len(created.ID)-2], "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, created.ID, decodeAPITask(t, rec).ID)
+
+	rec = doAPIRequest(t, handler, http.MethodGet, "/api/tasks/unknown", "", nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	for _, body := range []string{`{"title": ""}`, `{"title": "x", "priority": "AA"}`, `{"title": "x", "unknown": 1}`, `not json`} {
+		rec = doAPIRequest(t, handler, http.MethodPost, "/api/tasks", body, nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code, body)
+		require.Contains(t, rec.Body.String(), `"error"`)
+	}
+}
+
+func TestAPIRejectsCrossSiteRequests(t *testing.T) {
+	taskFile, handler := newAPITestServer(t)
+	task := internal.NewTask("Keep me")
+	require.NoError(t, taskFile.AddTask(task))
+
+	// Other pages can send a text/plain POST without a preflight request
+	rec := doAPIRequest(t, handler, http.MethodPost, "/api/tasks", `{"title": "Injected"}`,
+		map[string]string{"Content-Type": "text/plain"})
+	require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	rec = doAPIRequest(t, handler, http.MethodPost, "/api/tasks/"+task.ID+"/status", `{"status": "DONE"}`,
+		map[string]string{"Origin": "https://evil.example"})
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	// DNS rebinding: the page's own origin, but not this server's host
+	req := httptest.NewRequest(http.MethodDelete, "/api/tasks/"+task.ID, nil)
+	req.Host = "evil.example:7676"
+	req.Header.Set("Origin", "http://evil.example:7676")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Equal(t, internal.StatusTODO, tasks[0].Status)
+
+	// The server's own pages, and clients like curl which send no Origin, are allowed
+	rec = doAPIRequest(t, handler, http.MethodPost, "/api/tasks/"+task.ID+"/status", `{"status": "DONE"}`,
+		map[string]string{"Origin": "http://127.0.0.1:7676", "Content-Type": "application/json; charset=utf-8"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = doAPIRequest(t, handler, http.MethodDelete, "/api/tasks/"+task.ID, "", nil)
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+}
+
+func TestAPIPatchTaskWithETag(t *testing.T) {
+	taskFile, handler := newAPITestServer(t)
+	task := internal.ParseTask("Original +work")
+	require.NoError(t, taskFile.AddTask(task))
+
+	rec := doAPIRequest(t, handler, http.MethodGet, "/api/tasks/"+task.ID, "", nil)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rec = doAPIRequest(t, handler, http.MethodPatch, "/api/tasks/"+task.ID,
+		`{"title": "Renamed +docs", "status": "doing", "due_date": "2030-01-31"}`,
+		map[string]string{"If-Match": etag})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	updated := decodeAPITask(t, rec)
+	require.Equal(t, "Renamed", updated.Title)
+	require.Equal(t, []string{"work", "docs"}, updated.Projects)
+	require.Equal(t, internal.StatusDOING, updated.Status)
+	require.Equal(t, "2030-01-31", updated.DueDate.Format("2006-01-02"))
+	require.NotEqual(t, etag, rec.Header().Get("ETag"))
+
+	// The old ETag is stale now
+	rec = doAPIRequest(t, handler, http.MethodPatch, "/api/tasks/"+task.ID,
+		`{"note": "lost update"}`, map[string]string{"If-Match": etag})
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.Equal(t, taskETag(&updated), rec.Header().Get("ETag"))
+	require.Contains(t, rec.Body.String(), "Renamed")
+
+	// Clearing a date
+	rec = doAPIRequest(t, handler, http.MethodPatch, "/api/tasks/"+task.ID, `{"due_date": ""}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Nil(t, decodeAPITask(t, rec).DueDate)
+
+	rec = doAPIRequest(t, handler, http.MethodPatch, "/api/tasks/"+task.ID, `{"status": "LATER"}`, nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAPIPatchTitleKeepsUnresolvedRefs(t *testing.T) {
+	taskFile, handler := newAPITestServer(t)
+	blocker := internal.NewTask("Blocker")
+	task := internal.NewTask("Ship")
+	require.NoError(t, taskFile.AddTasks([]internal.Task{*blocker, *task}))
+
+	// after:lunch matches no task, so it's part of the title
+	rec := doAPIRequest(t, handler, http.MethodPatch, "/api/tasks/"+task.ID, `{"title": "ship after:lunch"}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	updated := decodeAPITask(t, rec)
+	require.Equal(t, "ship after:lunch", updated.Title)
+	require.Empty(t, updated.BlockedBy)
+
+	rec = doAPIRequest(t, handler, http.MethodPatch, "/api/tasks/"+task.ID,
+		`{"title": "ship after:`+blocker.ID+`"}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	updated = decodeAPITask(t, rec)
+	require.Equal(t, "ship", updated.Title)
+	require.Equal(t, []string{blocker.ID}, updated.BlockedBy)
+	require.Equal(t, internal.StatusWAITING, updated.Status)
+}
+
+func TestAPIPatchTaskMergesWithBase(t *testing.T) {
+	taskFile, handler := newAPITestServer(t)
+	task := internal.ParseTask("Shared task")
+	require.NoError(t, taskFile.AddTask(task))
+
+	rec := doAPIRequest(t, handler, http.MethodGet, "/api/tasks/"+task.ID, "", nil)
+	etag := rec.Header().Get("ETag")
+
+	// Someone else changes the note
+	require.NoError(t, taskFile.UpdateTaskWithConflictCheck(task.ID, task.Updated, func(t *internal.Task) {
+		t.Note = "Changed elsewhere"
+	}))
+
+	// A patch of another field is merged despite the stale ETag
+	rec = doAPIRequest(t, handler, http.MethodPatch, "/api/tasks/"+task.ID,
+		`{"priority": "a", "base": {"priority": ""}}`, map[string]string{"If-Match": etag})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	merged := decodeAPITask(t, rec)
+	require.Equal(t, "A", merged.Priority)
+	require.Equal(t, "Changed elsewhere", merged.Note)
+
+	// A patch of the same field conflicts and lists it
+	rec = doAPIRequest(t, handler, http.MethodPatch, "/api/tasks/"+task.ID,
+		`{"note": "Mine", "base": {"note": ""}}`, map[string]string{"If-Match": etag})
+	require.Equal(t, http.StatusConflict, rec.Code)
+	var body struct {
+		Fields []string      `json:"fields"`
+		Task   internal.Task `json:"task"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, []string{"note"}, body.Fields)
+	require.Equal(t, "Changed elsewhere", body.Task.Note)
+	require.Equal(t, taskETag(&merged), rec.Header().Get("ETag"))
+
+	// The base must say where every patched field started from
+	rec = doAPIRequest(t, handler, http.MethodPatch, "/api/tasks/"+task.ID,
+		`{"note": "Mine", "priority": "b", "base": {"note": ""}}`, map[string]string{"If-Match": etag})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAPITaskHistory(t *testing.T) {
+	taskFile, handler := newAPITestServer(t)
+	taskFile.Actor = "alice (web)"
+	task := internal.ParseTask("Tracked task")
+	require.NoError(t, taskFile.AddTask(task))
+
+	rec := doAPIRequest(t, handler, http.MethodPost, "/api/tasks/"+task.ID+"/status", `{"status": "waiting"}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doAPIRequest(t, handler, http.MethodGet, "/api/tasks/"+task.ID+"/history", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var history []historyEntry
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &history))
+	require.Len(t, history, 2)
+	require.Equal(t, `created "Tracked task"`, history[0].Change)
+	require.Equal(t, "status", history[1].Field)
+	require.Equal(t, "status: TODO → WAITING", history[1].Change)
+	require.Equal(t, "alice (web)", history[1].Actor)
+
+	rec = doAPIRequest(t, handler, http.MethodGet, "/api/tasks/unknown/history", "", nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAPITaskStatusAndDelete(t *testing.T) {
+	taskFile, handler := newAPITestServer(t)
+	task := internal.NewTask("Finish me")
+	require.NoError(t, taskFile.AddTask(task))
+
+	rec := doAPIRequest(t, handler, http.MethodPost, "/api/tasks/"+task.ID+"/status", `{"status": "DONE"}`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	done := decodeAPITask(t, rec)
+	require.Equal(t, internal.StatusDONE, done.Status)
+	require.NotNil(t, done.CompletedAt)
+
+	rec = doAPIRequest(t, handler, http.MethodDelete, "/api/tasks/"+task.ID, "", map[string]string{"If-Match": `"1"`})
+	require.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = doAPIRequest(t, handler, http.MethodDelete, "/api/tasks/"+task.ID, "", map[string]string{"If-Match": taskETag(&done)})
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Empty(t, tasks)
+}
+
+func TestAPIBulk(t *testing.T) {
+	taskFile, handler := newAPITestServer(t)
+	existing := internal.NewTask("Existing")
+	require.NoError(t, taskFile.AddTask(existing))
+
+	body := `[
+		{"op": "create", "task": {"title": "New task +bot"}},
+		{"op": "status", "id": "` + existing.ID + `", "status": "DOING"},
+		{"op": "update", "id": "` + existing.ID + `", "etag": "\"1\"", "patch": {"note": "stale"}},
+		{"op": "delete", "id": "missing"},
+		{"op": "explode"}
+	]`
+	rec := doAPIRequest(t, handler, http.MethodPost, "/api/tasks/bulk", body, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var results []bulkResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+	require.Len(t, results, 5)
+	require.Equal(t, http.StatusCreated, results[0].Status)
+	require.Equal(t, "New task", results[0].Task.Title)
+	require.Equal(t, http.StatusOK, results[1].Status)
+	require.Equal(t, internal.StatusDOING, results[1].Task.Status)
+	require.Equal(t, http.StatusConflict, results[2].Status)
+	require.Equal(t, http.StatusNotFound, results[3].Status)
+	require.Equal(t, http.StatusBadRequest, results[4].Status)
+
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"taskeru/internal"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CalendarEntry is a task on a day of the calendar, placed by its due or scheduled date
+type CalendarEntry struct {
+	Task    internal.Task
+	Kind    string // "due" or "scheduled", the date which is changed when the entry is dragged
+	Overdue bool
+}
+
+// CalendarDay is a cell of the month grid
+type CalendarDay struct {
+	Date    string // YYYY-MM-DD
+	Day     int
+	InMonth bool // False for the days of the previous and next month which fill the first and last week
+	Today   bool
+	Weekend bool
+	Entries []CalendarEntry
+}
+
+func (c *Controller) calendarHandler(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	targetYear, targetMonth := now.Year(), int(now.Month())
+
+	if year, month := chi.URLParam(r, "year"), chi.URLParam(r, "month"); year != "" && month != "" {
+		var err error
+		targetYear, err = strconv.Atoi(year)
+		if err != nil {
+			http.Error(w, "invalid year: "+year, http.StatusBadRequest)
+			return
+		}
+		targetMonth, err = strconv.Atoi(month)
+		if err != nil || targetMonth < 1 || targetMonth > 12 {
+			http.Error(w, "invalid month: "+month, http.StatusBadRequest)
+			return
+		}
+	}
+
+	targetDate := time.Date(targetYear, time.Month(targetMonth), 1, 0, 0, 0, 0, time.Local)
+
+	tasks, err := c.taskFile.LoadTasks()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	internal.SortTasks(tasks)
+
+	prev := targetDate.AddDate(0, -1, 0)
+	next := targetDate.AddDate(0, 1, 0)
+	data := struct {
+		Title      string
+		Year       int
+		Month      int
+		MonthName  string
+		Weekdays   []string
+		Weeks      [][]CalendarDay
+		ActiveView string
+		PrevMonth  YearMonth
+		NextMonth  YearMonth
+		ThisMonth  YearMonth
+	}{
+		Title:      fmt.Sprintf("Taskeru - Calendar %d/%02d", targetYear, targetMonth),
+		Year:       targetYear,
+		Month:      targetMonth,
+		MonthName:  targetDate.Month().String(),
+		Weekdays:   []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
+		Weeks:      buildCalendar(tasks, targetDate, now),
+		ActiveView: "calendar",
+		PrevMonth:  YearMonth{Year: prev.Year(), Month: int(prev.Month())},
+		NextMonth:  YearMonth{Year: next.Year(), Month: int(next.Month())},
+		ThisMonth:  YearMonth{Year: now.Year(), Month: int(now.Month())},
+	}
+
+	if err := templates.ExecuteTemplate(w, "calendar_page.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// calendarFeedHandler serves the tasks with due or scheduled dates as iCalendar, so that calendar apps
+// can subscribe to them. ?project=name limits the feed to a project and ?q= takes a filter expression.
+func (c *Controller) calendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	query, err := internal.ParseQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := c.taskFile.LoadTasks()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	project := strings.TrimPrefix(r.URL.Query().Get("project"), "+")
+	tasks = internal.FilterTasksByQuery(listedTasks(tasks, ListOptions{ProjectFilter: project}), query)
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	_ = writeICS(w, tasks, calendarName(project))
+}
+
+// buildCalendar returns the weeks, Monday to Sunday, which cover the month, with the tasks
+// due or scheduled on each day. A task scheduled and due on different days is shown on both.
+// Tasks given up on (WONTDO) are left out.
+func buildCalendar(tasks []internal.Task, month time.Time, now time.Time) [][]CalendarDay {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	// Weeks start on Monday
+	start := first.AddDate(0, 0, -((int(first.Weekday()) + 6) % 7))
+	today := now.Format("2006-01-02")
+
+	entriesByDate := make(map[string][]CalendarEntry)
+	for _, task := range tasks {
+		if task.Status == internal.StatusWONTDO {
+			continue
+		}
+		if task.ScheduledDate != nil && (task.DueDate == nil || !sameDay(*task.ScheduledDate, *task.DueDate)) {
+			date := task.ScheduledDate.Format("2006-01-02")
+			entriesByDate[date] = append(entriesByDate[date], CalendarEntry{Task: task, Kind: "scheduled"})
+		}
+		if task.DueDate != nil {
+			date := task.DueDate.Format("2006-01-02")
+			entriesByDate[date] = append(entriesByDate[date], CalendarEntry{
+				Task:    task,
+				Kind:    "due",
+				Overdue: !task.IsCompleted() && task.DueDate.Before(now),
+			})
+		}
+	}
+
+	var weeks [][]CalendarDay
+	for day := start; day.Before(first.AddDate(0, 1, 0)); {
+		week := make([]CalendarDay, 7)
+		for i := range week {
+			date := day.Format("2006-01-02")
+			week[i] = CalendarDay{
+				Date:    date,
+				Day:     day.Day(),
+				InMonth: day.Month() == first.Month(),
+				Today:   date == today,
+				Weekend: day.Weekday() == time.Saturday || day.Weekday() == time.Sunday,
+				Entries: entriesByDate[date],
+			}
+			day = day.AddDate(0, 0, 1)
+		}
+		weeks = append(weeks, week)
+	}
+	return weeks
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
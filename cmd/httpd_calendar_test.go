@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"taskeru/internal"
+)
+
+func TestBuildCalendar(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.Local)
+	date := func(day, hour int) *time.Time {
+		d := time.Date(2025, 3, day, hour, 0, 0, 0, time.Local)
+		return &d
+	}
+
+	tasks := []internal.Task{
+		{ID: "overdue", Title: "Overdue", Status: internal.StatusTODO, DueDate: date(10, 23)},
+		{ID: "done", Title: "Done late", Status: internal.StatusDONE, DueDate: date(10, 23)},
+		{ID: "both", Title: "Both dates", Status: internal.StatusTODO, ScheduledDate: date(14, 0), DueDate: date(20, 23)},
+		{ID: "same-day", Title: "Same day", Status: internal.StatusTODO, ScheduledDate: date(20, 0), DueDate: date(20, 23)},
+		{ID: "wontdo", Title: "Given up", Status: internal.StatusWONTDO, DueDate: date(20, 23)},
+	}
+
+	weeks := buildCalendar(tasks, time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local), now)
+
+	// March 2025 starts on a Saturday, so the grid starts on Monday, February 24
+	require.Len(t, weeks, 6)
+	require.Equal(t, "2025-02-24", weeks[0][0].Date)
+	require.False(t, weeks[0][0].InMonth)
+	require.Equal(t, "2025-04-06", weeks[5][6].Date)
+
+	days := make(map[string]CalendarDay)
+	for _, week := range weeks {
+		for _, day := range week {
+			days[day.Date] = day
+		}
+	}
+	require.True(t, days["2025-03-12"].Today)
+	require.True(t, days["2025-03-15"].Weekend)
+
+	entries := func(date string) []string {
+		var result []string
+		for _, entry := range days[date].Entries {
+			result = append(result, entry.Task.ID+"/"+entry.Kind)
+		}
+		return result
+	}
+	require.Equal(t, []string{"overdue/due", "done/due"}, entries("2025-03-10"))
+	require.True(t, days["2025-03-10"].Entries[0].Overdue)
+	require.False(t, days["2025-03-10"].Entries[1].Overdue)
+	require.Equal(t, []string{"both/scheduled"}, entries("2025-03-14"))
+	require.Equal(t, []string{"both/due", "same-day/due"}, entries("2025-03-20"))
+}
+
+func TestCalendarHandler(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	due := time.Date(2025, 3, 20, 23, 59, 59, 0, time.Local)
+	task := internal.NewTask("Ship <release>")
+	task.DueDate = &due
+	require.NoError(t, taskFile.AddTask(task))
+
+	r := chi.NewRouter()
+	NewController(taskFile).registerRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/calendar/2025/3", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	require.Contains(t, body, `data-date="2025-03-20"`)
+	require.Contains(t, body, `data-live-key="`+task.ID+`-due"`)
+	require.Contains(t, body, `data-kind="due"`)
+	require.Contains(t, body, "Ship &lt;release&gt;")
+	require.Contains(t, body, "calendar-entry due todo overdue", "a task due in the past is overdue")
+
+	for _, path := range []string{"/calendar/2025/13", "/calendar/year/3"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusBadRequest, rec.Code, path)
+	}
+}
+
+func TestCalendarDropReschedules(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	scheduled := time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)
+	task := internal.NewTask("Plan sprint")
+	task.ScheduledDate = &scheduled
+	require.NoError(t, taskFile.AddTask(task))
+
+	r := chi.NewRouter()
+	NewController(taskFile).registerRoutes(r)
+
+	// The page sends the same request when an entry is dropped on another day
+	rec := doAPIRequest(t, r, http.MethodPatch, "/api/tasks/"+task.ID, `{"scheduled_date": "2025-03-17"}`,
+		map[string]string{"If-Match": taskETag(task)})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/calendar/2025/3", nil))
+	body := rec.Body.String()
+	day := body[strings.Index(body, `data-date="2025-03-17"`):]
+	day = day[:strings.Index(day, `data-date="2025-03-18"`)]
+	require.Contains(t, day, "Plan sprint")
+}
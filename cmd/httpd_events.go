@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// taskFileWatchInterval is how often the task file is checked for changes
+const taskFileWatchInterval = 500 * time.Millisecond
+
+// eventBroker fans out task file changes to the connected browsers
+type eventBroker struct {
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{clients: make(map[chan struct{}]struct{})}
+}
+
+func (b *eventBroker) subscribe() chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan struct{}, 1)
+	b.clients[ch] = struct{}{}
+	return ch
+}
+
+func (b *eventBroker) unsubscribe(ch chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.clients, ch)
+}
+
+// broadcast notifies all clients. Slow clients which still have a pending notification are skipped.
+func (b *eventBroker) broadcast() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// watchTaskFile broadcasts changes of the task file until ctx is done
+func (c *Controller) watchTaskFile(ctx context.Context, interval time.Duration) {
+	for range c.taskFile.Watch(ctx, interval) {
+		c.events.broadcast()
+	}
+}
+
+// eventsHandler streams a "tasks" Server-Sent Event whenever the task file changes
+func (c *Controller) eventsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := c.events.subscribe()
+	defer c.events.unsubscribe(ch)
+
+	_, _ = fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ch:
+			_, _ = fmt.Fprint(w, "event: tasks\ndata: changed\n\n")
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"taskeru/internal"
+)
+
+func TestEventsAreSentWhenTaskFileChanges(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	controller := NewController(taskFile)
+	r := chi.NewRouter()
+	controller.registerRoutes(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go controller.watchTaskFile(ctx, 10*time.Millisecond)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, ": connected\n", line)
+
+	// Change the file like another process would
+	require.NoError(t, taskFile.AddTask(internal.NewTask("Added elsewhere")))
+
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err, "expected an event before the timeout")
+		if strings.HasPrefix(line, "event: tasks") {
+			break
+		}
+	}
+}
+
+func TestPagesSubscribeToLiveUpdates(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	task := internal.NewTask("Live task")
+	require.NoError(t, taskFile.AddTask(task))
+
+	r := chi.NewRouter()
+	NewController(taskFile).registerRoutes(r)
+
+	for _, path := range []string{"/kanban", "/daily"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		require.Contains(t, rec.Body.String(), "new EventSource('/events')", path)
+		require.Contains(t, rec.Body.String(), "data-live-container", path)
+	}
+}
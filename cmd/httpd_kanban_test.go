@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"taskeru/internal"
 	"testing"
 	"time"
@@ -119,3 +122,121 @@ func TestGroupTasksByStatusHandlesNilCompletedAt(t *testing.T) {
 		t.Errorf("Expected 1 WONTDO task without CompletedAt, got %d", len(result["WONTDO"]))
 	}
 }
+
+func TestKanbanShowsSubtaskProgress(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+
+	parent := internal.NewTask("Parent task")
+	done := internal.NewTask("Done subtask")
+	done.ParentID = parent.ID
+	done.SetStatus(internal.StatusDONE)
+	open := internal.NewTask("Open subtask")
+	open.ParentID = parent.ID
+	if err := taskFile.AddTasks([]internal.Task{*parent, *done, *open}); err != nil {
+		t.Fatalf("Failed to save tasks: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/kanban", nil)
+	rec := httptest.NewRecorder()
+	NewController(taskFile).kanbanHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "1/2 subtasks done") {
+		t.Errorf("Expected subtask progress in kanban page")
+	}
+	if strings.Count(rec.Body.String(), "subtasks done") != 1 {
+		t.Errorf("Expected progress only on the parent card")
+	}
+}
+
+func TestKanbanAndAPIFilterByQuery(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+
+	work := internal.ParseTask("Write report +work")
+	work.SetStatus(internal.StatusDOING)
+	home := internal.ParseTask("Clean kitchen +home")
+	if err := taskFile.AddTasks([]internal.Task{*work, *home}); err != nil {
+		t.Fatalf("Failed to save tasks: %v", err)
+	}
+
+	controller := NewController(taskFile)
+
+	req := httptest.NewRequest(http.MethodGet, "/kanban?q=%2Bwork", nil)
+	rec := httptest.NewRecorder()
+	controller.kanbanHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Write report") || strings.Contains(rec.Body.String(), "Clean kitchen") {
+		t.Errorf("Expected only +work tasks in kanban page")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/tasks?q=status:DOING", nil)
+	rec = httptest.NewRecorder()
+	controller.apiTasksHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Write report") || strings.Contains(rec.Body.String(), "Clean kitchen") {
+		t.Errorf("Expected only DOING tasks from the API, got %s", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/tasks?q=prio:%3C%3D1", nil)
+	rec = httptest.NewRecorder()
+	controller.apiTasksHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid query, got %d", rec.Code)
+	}
+}
+
+func TestKanbanCardsAreEditable(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	task := internal.NewTask("Editable task")
+	task.Note = "Some <note>"
+	if err := taskFile.AddTask(task); err != nil {
+		t.Fatalf("Failed to save tasks: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/kanban", nil)
+	rec := httptest.NewRecorder()
+	NewController(taskFile).kanbanHandler(rec, req)
+
+	body := rec.Body.String()
+	for _, expected := range []string{
+		`data-status="DOING"`,
+		`data-id="` + task.ID + `"`,
+		`data-etag="` + strings.ReplaceAll(taskETag(task), `"`, "&#34;") + `"`,
+		`class="quick-add"`,
+		`Some &lt;note&gt;</textarea>`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected %q in kanban page", expected)
+		}
+	}
+}
+
+func TestKanbanShowsEffort(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	for _, title := range []string{"Write report est:2h pts:3", "Review est:30m pts:1"} {
+		if err := taskFile.AddTask(internal.ParseTask(title)); err != nil {
+			t.Fatalf("Failed to save tasks: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/kanban", nil)
+	rec := httptest.NewRecorder()
+	NewController(taskFile).kanbanHandler(rec, req)
+
+	body := rec.Body.String()
+	for _, expected := range []string{
+		`data-live-key="effort-TODO" title="Remaining estimate and points">est:2h30m pts:4</span>`,
+		`⏳ est:2h pts:3`,
+		`⏳ est:30m pts:1`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected %q in kanban page", expected)
+		}
+	}
+}
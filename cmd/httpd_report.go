@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// weeklyReportHandler shows what was completed this week, what is in progress and what is due next.
+// ?since=<date> changes the start of the period, like `taskeru report weekly --since`.
+func (c *Controller) weeklyReportHandler(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		if since, err = parseReportDate(value); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Tasks completed this week may already be archived
+	tasks, err := c.taskFile.LoadTasksWithArchive()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	report, err := buildReviewReport(tasks, "weekly", since, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The same text as `taskeru report weekly --format md`, for the copy button
+	var markdown strings.Builder
+	if err := writeReviewReport(&markdown, report, true); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Title      string
+		Report     reviewReport
+		Markdown   string
+		ActiveView string
+	}{
+		Title:      "Taskeru - " + report.Title,
+		Report:     report,
+		Markdown:   markdown.String(),
+		ActiveView: "weekly",
+	}
+
+	if err := templates.ExecuteTemplate(w, "weekly_page.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
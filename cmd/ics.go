@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"taskeru/internal"
+)
+
+// icsStatuses maps task statuses to the STATUS of a VTODO
+var icsStatuses = map[string]string{
+	internal.StatusTODO:    "NEEDS-ACTION",
+	internal.StatusDOING:   "IN-PROCESS",
+	internal.StatusWAITING: "NEEDS-ACTION",
+	internal.StatusDONE:    "COMPLETED",
+	internal.StatusWONTDO:  "CANCELLED",
+}
+
+// writeICS writes tasks with a due or scheduled date as an iCalendar (RFC 5545) feed.
+// Tasks with a due date become a VTODO, due on that day and starting on the scheduled date.
+// Tasks with only a scheduled date become an all-day VEVENT on that day.
+// The task ID is the UID, so calendar apps update the entries when tasks change.
+func writeICS(out io.Writer, tasks []internal.Task, name string) error {
+	w := &icsWriter{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//taskeru//taskeru//EN")
+	w.line("CALSCALE:GREGORIAN")
+	w.property("X-WR-CALNAME", name)
+
+	for _, task := range tasks {
+		switch {
+		case task.DueDate != nil:
+			w.line("BEGIN:VTODO")
+			w.taskProperties(task)
+			if task.ScheduledDate != nil && !task.ScheduledDate.After(*task.DueDate) {
+				w.line("DTSTART;VALUE=DATE:" + icsDate(*task.ScheduledDate))
+			}
+			w.line("DUE;VALUE=DATE:" + icsDate(*task.DueDate))
+			w.line("STATUS:" + icsStatuses[task.Status])
+			if task.Status == internal.StatusDONE && task.CompletedAt != nil {
+				w.line("COMPLETED:" + icsTime(*task.CompletedAt))
+			}
+			if priority := icsPriority(task.Priority); priority > 0 {
+				w.line(fmt.Sprintf("PRIORITY:%d", priority))
+			}
+			w.line("END:VTODO")
+		case task.ScheduledDate != nil:
+			w.line("BEGIN:VEVENT")
+			w.taskProperties(task)
+			w.line("DTSTART;VALUE=DATE:" + icsDate(*task.ScheduledDate))
+			w.line("DTEND;VALUE=DATE:" + icsDate(task.ScheduledDate.AddDate(0, 0, 1)))
+			w.line("TRANSP:TRANSPARENT")
+			if task.Status == internal.StatusWONTDO {
+				w.line("STATUS:CANCELLED")
+			}
+			w.line("END:VEVENT")
+		}
+	}
+
+	w.line("END:VCALENDAR")
+	_, err := io.WriteString(out, w.String())
+	return err
+}
+
+// icsWriter builds iCalendar content lines, which end with CRLF and are folded at 75 octets
+type icsWriter struct {
+	strings.Builder
+}
+
+func (w *icsWriter) line(line string) {
+	for len(line) > 75 {
+		// Don't split UTF-8 characters
+		cut := 75
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n")
+		line = " " + line[cut:]
+	}
+	w.WriteString(line + "\r\n")
+}
+
+// property writes a TEXT property, escaped as RFC 5545 requires
+func (w *icsWriter) property(name, value string) {
+	escaper := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	w.line(name + ":" + escaper.Replace(value))
+}
+
+// taskProperties writes the properties VTODO and VEVENT have in common
+func (w *icsWriter) taskProperties(task internal.Task) {
+	w.line("UID:" + task.ID)
+	w.line("DTSTAMP:" + icsTime(task.Updated))
+	w.line("CREATED:" + icsTime(task.Created))
+	w.line("LAST-MODIFIED:" + icsTime(task.Updated))
+	w.property("SUMMARY", task.Title)
+	if task.Note != "" {
+		w.property("DESCRIPTION", task.Note)
+	}
+	if len(task.Projects) > 0 {
+		escaper := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`)
+		var categories []string
+		for _, project := range task.Projects {
+			categories = append(categories, escaper.Replace(project))
+		}
+		w.line("CATEGORIES:" + strings.Join(categories, ","))
+	}
+}
+
+func icsDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icsPriority maps A-I to the iCalendar priorities 1 (highest) to 9, and lower priorities to 9.
+// Tasks without priority get 0, which means undefined.
+func icsPriority(priority string) int {
+	if priority == "" {
+		return 0
+	}
+	return min(int(priority[0]-'A')+1, 9)
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"taskeru/internal"
+)
+
+func TestWriteICS(t *testing.T) {
+	created := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	due := time.Date(2025, 3, 20, 23, 59, 59, 0, time.Local)
+	scheduled := time.Date(2025, 3, 17, 0, 0, 0, 0, time.Local)
+	completed := time.Date(2025, 3, 18, 12, 30, 0, 0, time.UTC)
+
+	tasks := []internal.Task{
+		{
+			ID: "todo-id", Title: "Ship release, v2; final", Status: internal.StatusDOING, Priority: "B",
+			Projects: []string{"work"}, Note: "Line 1\nLine 2",
+			DueDate: &due, ScheduledDate: &scheduled, Created: created, Updated: created,
+		},
+		{ID: "event-id", Title: "Plan sprint", Status: internal.StatusWONTDO, ScheduledDate: &scheduled, Created: created, Updated: created},
+		{ID: "done-id", Title: "Done", Status: internal.StatusDONE, DueDate: &due, CompletedAt: &completed, Created: created, Updated: created},
+		{ID: "undated-id", Title: "No dates", Status: internal.StatusTODO, Created: created, Updated: created},
+	}
+
+	var b strings.Builder
+	require.NoError(t, writeICS(&b, tasks, "taskeru"))
+	output := b.String()
+
+	require.True(t, strings.HasPrefix(output, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	require.True(t, strings.HasSuffix(output, "END:VCALENDAR\r\n"))
+	require.Equal(t, 2, strings.Count(output, "BEGIN:VTODO"))
+	require.Equal(t, 1, strings.Count(output, "BEGIN:VEVENT"))
+	require.NotContains(t, output, "undated-id")
+
+	for _, expected := range []string{
+		"UID:todo-id\r\n",
+		"SUMMARY:Ship release\\, v2\\; final\r\n",
+		"DESCRIPTION:Line 1\\nLine 2\r\n",
+		"CATEGORIES:work\r\n",
+		"DTSTART;VALUE=DATE:20250317\r\n",
+		"DUE;VALUE=DATE:20250320\r\n",
+		"STATUS:IN-PROCESS\r\n",
+		"PRIORITY:2\r\n",
+		"DTSTAMP:20250301T090000Z\r\n",
+		"UID:event-id\r\nDTSTAMP",
+		"DTEND;VALUE=DATE:20250318\r\n",
+		"STATUS:CANCELLED\r\n",
+		"STATUS:COMPLETED\r\nCOMPLETED:20250318T123000Z\r\n",
+	} {
+		require.Contains(t, output, expected)
+	}
+}
+
+func TestICSLinesAreFolded(t *testing.T) {
+	due := time.Date(2025, 3, 20, 23, 59, 59, 0, time.Local)
+	title := strings.Repeat("長いタイトル", 10)
+	tasks := []internal.Task{{ID: "id", Title: title, Status: internal.StatusTODO, DueDate: &due}}
+
+	var b strings.Builder
+	require.NoError(t, writeICS(&b, tasks, "taskeru"))
+
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		require.LessOrEqual(t, len(line), 75)
+	}
+	// Unfolding gives the original line back
+	require.Contains(t, strings.ReplaceAll(b.String(), "\r\n ", ""), "SUMMARY:"+title+"\r\n")
+}
+
+func TestCalendarFeed(t *testing.T) {
+	taskFile, handler := newAPITestServer(t)
+	for _, title := range []string{"Write report +work due:friday", "Buy milk +home due:friday", "Someday +work"} {
+		require.NoError(t, taskFile.AddTask(internal.ParseTask(title)))
+	}
+
+	rec := doAPIRequest(t, handler, http.MethodGet, "/calendar.ics", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/calendar; charset=utf-8", rec.Header().Get("Content-Type"))
+	require.Contains(t, rec.Body.String(), "SUMMARY:Write report")
+	require.Contains(t, rec.Body.String(), "SUMMARY:Buy milk")
+	require.NotContains(t, rec.Body.String(), "Someday")
+
+	rec = doAPIRequest(t, handler, http.MethodGet, "/calendar.ics?project=work", "", nil)
+	require.Contains(t, rec.Body.String(), "X-WR-CALNAME:taskeru +work")
+	require.Contains(t, rec.Body.String(), "SUMMARY:Write report")
+	require.NotContains(t, rec.Body.String(), "Buy milk")
+
+	rec = doAPIRequest(t, handler, http.MethodGet, "/calendar.ics?q=prio:%3C%3D1", "", nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestExportICSCommand(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	for _, title := range []string{"Write report +work due:friday", "Buy milk +home sched:monday"} {
+		require.NoError(t, taskFile.AddTask(internal.ParseTask(title)))
+	}
+
+	output := captureStdout(t, func() {
+		require.NoError(t, ExportCommand(taskFile, ListOptions{ProjectFilter: "home"}, []string{"ics"}))
+	})
+	require.Contains(t, output, "BEGIN:VEVENT")
+	require.Contains(t, output, "SUMMARY:Buy milk")
+	require.NotContains(t, output, "Write report")
+
+	output = captureStdout(t, func() {
+		require.NoError(t, ExportCommand(taskFile, ListOptions{}, []string{"ics", "+work"}))
+	})
+	require.Contains(t, output, "SUMMARY:Write report")
+	require.NotContains(t, output, "Buy milk")
+
+	require.Error(t, ExportCommand(taskFile, ListOptions{}, []string{"vcard"}))
+}
@@ -0,0 +1,537 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"taskeru/internal"
+)
+
+// ImportCommand adds tasks exported by other tools:
+// taskeru import --from todotxt|taskwarrior|csv [--dry-run] <file>
+// Tasks which are already in the task file or the archive are skipped, so a file can be imported again.
+func ImportCommand(taskFile *internal.TaskFile, args []string) error {
+	var from, path string
+	dryRun := false
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--from" && i+1 < len(args):
+			from = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--from="):
+			from = strings.TrimPrefix(args[i], "--from=")
+		case args[i] == "--dry-run" || args[i] == "-n":
+			dryRun = true
+		case path == "" && (args[i] == "-" || !strings.HasPrefix(args[i], "-")):
+			path = args[i]
+		default:
+			return fmt.Errorf("usage: import --from todotxt|taskwarrior|csv [--dry-run] <file>")
+		}
+	}
+	if from == "" || path == "" {
+		return fmt.Errorf("usage: import --from todotxt|taskwarrior|csv [--dry-run] <file>")
+	}
+
+	var parse func(io.Reader) ([]internal.Task, error)
+	switch from {
+	case "todotxt", "todo.txt":
+		parse = parseTodoTxt
+	case "taskwarrior", "tw":
+		parse = parseTaskwarrior
+	case "csv":
+		parse = parseTaskCSV
+	default:
+		return fmt.Errorf("unknown import format: %s (expected todotxt, taskwarrior or csv)", from)
+	}
+
+	in := os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		in = file
+	}
+	imported, err := parse(in)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	existing, err := taskFile.LoadTasks()
+	if err != nil {
+		return fmt.Errorf("failed to load tasks: %w", err)
+	}
+	archived, err := taskFile.LoadArchivedTasks()
+	if err != nil {
+		return fmt.Errorf("failed to load archived tasks: %w", err)
+	}
+	newTasks, duplicates := dedupeImportedTasks(append(existing, archived...), imported)
+	for _, task := range dropArchivedBlockers(newTasks, archived) {
+		_, _ = fmt.Fprintf(os.Stderr, "Warning: dropped the dependency of %q on an archived task\n", task.Title)
+	}
+
+	if dryRun {
+		for _, task := range newTasks {
+			fmt.Printf("Would import: %s\n", describeImportedTask(task))
+		}
+	}
+	for _, task := range duplicates {
+		fmt.Printf("Skipped duplicate: %s\n", task.Title)
+	}
+
+	if dryRun {
+		fmt.Printf("Would import %d task(s), %d duplicate(s) skipped (dry run)\n", len(newTasks), len(duplicates))
+		return nil
+	}
+	if len(newTasks) > 0 {
+		if err := taskFile.AddTasks(newTasks); err != nil {
+			return fmt.Errorf("failed to save tasks: %w", err)
+		}
+	}
+	fmt.Printf("Imported %d task(s), %d duplicate(s) skipped\n", len(newTasks), len(duplicates))
+	return nil
+}
+
+// dropArchivedBlockers removes blockers which are archived, as archived tasks are completed
+// and can't be linked. It returns the tasks which lost a blocker.
+func dropArchivedBlockers(tasks, archived []internal.Task) []internal.Task {
+	archivedIDs := make(map[string]bool, len(archived))
+	for _, task := range archived {
+		archivedIDs[task.ID] = true
+	}
+
+	var dropped []internal.Task
+	for i := range tasks {
+		if !slices.ContainsFunc(tasks[i].BlockedBy, func(id string) bool { return archivedIDs[id] }) {
+			continue
+		}
+		tasks[i].BlockedBy = slices.DeleteFunc(tasks[i].BlockedBy, func(id string) bool { return archivedIDs[id] })
+		if len(tasks[i].BlockedBy) == 0 {
+			tasks[i].BlockedBy = nil
+		}
+		dropped = append(dropped, tasks[i])
+	}
+	return dropped
+}
+
+// dedupeImportedTasks splits imported tasks into new ones and duplicates. A task is a duplicate
+// when a task with its ID exists, or one with the same title and projects, as todo.txt has no IDs.
+// References to tasks which are neither imported nor existing are dropped.
+func dedupeImportedTasks(existing, imported []internal.Task) (newTasks, duplicates []internal.Task) {
+	ids := make(map[string]bool)
+	keys := make(map[string]bool)
+	for _, task := range existing {
+		ids[task.ID] = true
+		keys[importKey(task)] = true
+	}
+
+	for _, task := range imported {
+		if ids[task.ID] || keys[importKey(task)] {
+			duplicates = append(duplicates, task)
+			continue
+		}
+		ids[task.ID] = true
+		keys[importKey(task)] = true
+		newTasks = append(newTasks, task)
+	}
+
+	for i := range newTasks {
+		newTasks[i].BlockedBy = slices.DeleteFunc(newTasks[i].BlockedBy, func(id string) bool { return !ids[id] })
+		if len(newTasks[i].BlockedBy) == 0 {
+			newTasks[i].BlockedBy = nil
+		}
+		if !ids[newTasks[i].ParentID] {
+			newTasks[i].ParentID = ""
+		}
+	}
+	return newTasks, duplicates
+}
+
+func importKey(task internal.Task) string {
+	projects := slices.Clone(task.Projects)
+	slices.Sort(projects)
+	return strings.TrimSpace(task.Title) + "\x00" + strings.Join(projects, ",")
+}
+
+func describeImportedTask(task internal.Task) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s", task.Status, task.Title)
+	for _, project := range task.Projects {
+		fmt.Fprintf(&b, " +%s", project)
+	}
+	if task.DueDate != nil {
+		fmt.Fprintf(&b, " due:%s", task.DueDate.Format("2006-01-02"))
+	}
+	if task.ScheduledDate != nil {
+		fmt.Fprintf(&b, " sched:%s", task.ScheduledDate.Format("2006-01-02"))
+	}
+	return b.String()
+}
+
+// newImportedTask returns a task with a new ID, created at the given time if it is known
+func newImportedTask(title string, created time.Time) internal.Task {
+	task := *internal.NewTask(title)
+	if !created.IsZero() {
+		task.Created = created
+	}
+	return task
+}
+
+// completeImportedTask marks a task as done without the side effects of SetStatus, like timers
+func completeImportedTask(task *internal.Task, completedAt time.Time) {
+	task.Status = internal.StatusDONE
+	if completedAt.IsZero() {
+		completedAt = task.Updated
+	}
+	task.CompletedAt = &completedAt
+}
+
+var todoTxtDateRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// parseTodoTxt reads the todo.txt format (https://github.com/todotxt/todo.txt).
+// Contexts (@home) become projects, as taskeru only has projects. due: sets the due date,
+// t: (threshold) the scheduled date and rec: the recurrence. Other key:value pairs stay in the title.
+func parseTodoTxt(r io.Reader) ([]internal.Task, error) {
+	var tasks []internal.Task
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		done := false
+		var completed, created time.Time
+		priority := ""
+		if fields[0] == "x" {
+			done = true
+			fields = fields[1:]
+			if len(fields) > 0 && todoTxtDateRegex.MatchString(fields[0]) {
+				completed, _ = time.ParseInLocation("2006-01-02", fields[0], time.Local)
+				fields = fields[1:]
+			}
+		} else if len(fields[0]) == 3 && fields[0][0] == '(' && fields[0][2] == ')' && fields[0][1] >= 'A' && fields[0][1] <= 'Z' {
+			priority = fields[0][1:2]
+			fields = fields[1:]
+		}
+		if len(fields) > 0 && todoTxtDateRegex.MatchString(fields[0]) {
+			created, _ = time.ParseInLocation("2006-01-02", fields[0], time.Local)
+			fields = fields[1:]
+		}
+
+		task := newImportedTask("", created)
+		var words []string
+		for _, field := range fields {
+			key, value, isPair := strings.Cut(field, ":")
+			switch {
+			case (field[0] == '+' || field[0] == '@') && len(field) > 1:
+				if project := field[1:]; !slices.Contains(task.Projects, project) {
+					task.Projects = append(task.Projects, project)
+				}
+			case isPair && key == "due":
+				if date, err := parseDateArg(value, false); err == nil && date != nil {
+					task.DueDate = date
+				} else {
+					words = append(words, field)
+				}
+			case isPair && key == "t":
+				if date, err := parseDateArg(value, true); err == nil && date != nil {
+					task.ScheduledDate = date
+				} else {
+					words = append(words, field)
+				}
+			case isPair && key == "rec":
+				if _, err := internal.ParseRecurrence(value); err == nil {
+					task.Recurrence = value
+				} else {
+					words = append(words, field)
+				}
+			case isPair && key == "pri" && done && len(value) == 1 && value[0] >= 'A' && value[0] <= 'Z':
+				// Completed tasks keep their priority as pri:A
+				priority = value
+			default:
+				words = append(words, field)
+			}
+		}
+
+		task.Title = strings.Join(words, " ")
+		if task.Title == "" {
+			continue
+		}
+		task.Priority = priority
+		if done {
+			completeImportedTask(&task, completed)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, scanner.Err()
+}
+
+// taskwarriorTask is a task of `task export`
+type taskwarriorTask struct {
+	UUID        string          `json:"uuid"`
+	Description string          `json:"description"`
+	Status      string          `json:"status"`
+	Entry       string          `json:"entry"`
+	Modified    string          `json:"modified"`
+	Start       string          `json:"start"`
+	End         string          `json:"end"`
+	Due         string          `json:"due"`
+	Scheduled   string          `json:"scheduled"`
+	Wait        string          `json:"wait"`
+	Recur       string          `json:"recur"`
+	Project     string          `json:"project"`
+	Tags        []string        `json:"tags"`
+	Priority    string          `json:"priority"`
+	Depends     json.RawMessage `json:"depends"` // A list, or a comma separated string before Taskwarrior 2.6
+	Annotations []struct {
+		Entry       string `json:"entry"`
+		Description string `json:"description"`
+	} `json:"annotations"`
+}
+
+// taskwarriorPriorities maps the priorities of Taskwarrior to letters
+var taskwarriorPriorities = map[string]string{"H": "A", "M": "B", "L": "C"}
+
+// parseTaskwarrior reads the JSON of `task export`, either an array or one task per line.
+// The UUIDs are kept as task IDs. Tags become projects next to the project,
+// annotations become timestamped sections of the note, and deleted tasks are skipped.
+func parseTaskwarrior(r io.Reader) ([]internal.Task, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var exported []taskwarriorTask
+	if err := json.Unmarshal(data, &exported); err != nil {
+		exported = nil
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSuffix(strings.TrimSpace(line), ",")
+			if line == "" || line == "[" || line == "]" {
+				continue
+			}
+			var t taskwarriorTask
+			if err := json.Unmarshal([]byte(line), &t); err != nil {
+				return nil, fmt.Errorf("invalid Taskwarrior export: %w", err)
+			}
+			exported = append(exported, t)
+		}
+	}
+
+	var tasks []internal.Task
+	for _, t := range exported {
+		// Recurring templates come with their pending instances
+		if t.Status == "deleted" || t.Status == "recurring" || strings.TrimSpace(t.Description) == "" {
+			continue
+		}
+
+		task := newImportedTask(strings.TrimSpace(t.Description), parseTaskwarriorTime(t.Entry))
+		if t.UUID != "" {
+			task.ID = t.UUID
+		}
+		if modified := parseTaskwarriorTime(t.Modified); !modified.IsZero() {
+			task.Updated = modified
+		}
+		if t.Project != "" {
+			task.Projects = append(task.Projects, t.Project)
+		}
+		for _, tag := range t.Tags {
+			if !slices.Contains(task.Projects, tag) {
+				task.Projects = append(task.Projects, tag)
+			}
+		}
+		task.Priority = taskwarriorPriorities[t.Priority]
+		if due := parseTaskwarriorTime(t.Due); !due.IsZero() {
+			task.DueDate = &due
+		}
+		scheduled := parseTaskwarriorTime(t.Scheduled)
+		if scheduled.IsZero() {
+			// Waiting tasks are hidden until the wait date, like scheduled tasks
+			scheduled = parseTaskwarriorTime(t.Wait)
+		}
+		if !scheduled.IsZero() {
+			task.ScheduledDate = &scheduled
+		}
+		if _, err := internal.ParseRecurrence(t.Recur); err == nil {
+			task.Recurrence = t.Recur
+		}
+		task.BlockedBy = parseTaskwarriorDepends(t.Depends)
+
+		var note strings.Builder
+		for _, annotation := range t.Annotations {
+			if entry := parseTaskwarriorTime(annotation.Entry); !entry.IsZero() {
+				note.WriteString(entry.Format("## 2006-01-02(Mon) 15:04\n\n"))
+			}
+			note.WriteString(annotation.Description + "\n\n")
+		}
+		task.Note = strings.TrimSpace(note.String())
+
+		switch {
+		case t.Status == "completed":
+			completeImportedTask(&task, parseTaskwarriorTime(t.End))
+		case t.Start != "":
+			task.Status = internal.StatusDOING
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// parseTaskwarriorTime parses the UTC timestamps of Taskwarrior, like 20250301T090000Z.
+// Invalid or empty values give the zero time.
+func parseTaskwarriorTime(value string) time.Time {
+	t, err := time.Parse("20060102T150405Z", value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.Local()
+}
+
+func parseTaskwarriorDepends(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var depends []string
+	if err := json.Unmarshal(raw, &depends); err == nil {
+		return depends
+	}
+	var joined string
+	if err := json.Unmarshal(raw, &joined); err == nil && joined != "" {
+		return strings.Split(joined, ",")
+	}
+	return nil
+}
+
+// parseTaskCSV reads the CSV written by `taskeru ls --format csv`. Only the title column is required,
+// so spreadsheets with a subset of the columns can be imported too.
+func parseTaskCSV(r io.Reader) ([]internal.Task, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("missing title column")
+	}
+
+	var tasks []internal.Task
+	for line, record := range records[1:] {
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		date := func(column string, scheduled bool) (*time.Time, error) {
+			text := value(column)
+			if t, err := time.Parse(time.RFC3339, text); err == nil {
+				return &t, nil
+			}
+			parsed, err := parseDateArg(text, scheduled)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", line+2, column, err)
+			}
+			return parsed, nil
+		}
+
+		if value("title") == "" {
+			continue
+		}
+		created, err := date("created", false)
+		if err != nil {
+			return nil, err
+		}
+		task := newImportedTask(value("title"), time.Time{})
+		if created != nil {
+			task.Created = *created
+		}
+		if id := value("id"); id != "" {
+			task.ID = id
+		}
+		if updated, err := date("updated", false); err != nil {
+			return nil, err
+		} else if updated != nil {
+			task.Updated = *updated
+		}
+
+		if status := strings.ToUpper(value("status")); status != "" {
+			if err := validateStatus(status); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line+2, err)
+			}
+			task.Status = status
+		}
+		if priority := strings.ToUpper(value("priority")); priority != "" {
+			if err := validatePriority(priority); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line+2, err)
+			}
+			task.Priority = priority
+		}
+		for _, project := range strings.Split(value("projects"), ",") {
+			if project = strings.TrimPrefix(strings.TrimSpace(project), "+"); project != "" {
+				task.Projects = append(task.Projects, project)
+			}
+		}
+		if task.DueDate, err = date("due_date", false); err != nil {
+			return nil, err
+		}
+		if task.ScheduledDate, err = date("scheduled_date", true); err != nil {
+			return nil, err
+		}
+		if task.CompletedAt, err = date("completed_at", false); err != nil {
+			return nil, err
+		}
+		if task.IsCompleted() && task.CompletedAt == nil {
+			completedAt := task.Updated
+			task.CompletedAt = &completedAt
+		}
+		if rule := strings.ToLower(value("recurrence")); rule != "" {
+			if _, err := internal.ParseRecurrence(rule); err != nil {
+				return nil, fmt.Errorf("line %d: recurrence: %w", line+2, err)
+			}
+			task.Recurrence = rule
+		}
+		task.ParentID = value("parent_id")
+		for _, id := range strings.Split(value("blocked_by"), ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				task.BlockedBy = append(task.BlockedBy, id)
+			}
+		}
+		task.Note = value("note")
+		if text := value("estimate_minutes"); text != "" {
+			minutes, err := strconv.Atoi(text)
+			if err != nil || minutes < 0 {
+				return nil, fmt.Errorf("line %d: estimate_minutes: invalid number: %s", line+2, text)
+			}
+			task.EstimateMinutes = minutes
+		}
+		if text := value("points"); text != "" {
+			points, err := strconv.ParseFloat(text, 64)
+			if err != nil || points < 0 {
+				return nil, fmt.Errorf("line %d: points: invalid number: %s", line+2, text)
+			}
+			task.Points = points
+		}
+
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"taskeru/internal"
+)
+
+func writeImportFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func findTaskByTitle(t *testing.T, tasks []internal.Task, title string) internal.Task {
+	t.Helper()
+	for _, task := range tasks {
+		if task.Title == title {
+			return task
+		}
+	}
+	t.Fatalf("task %q not found", title)
+	return internal.Task{}
+}
+
+func TestParseTodoTxt(t *testing.T) {
+	input := `(A) 2025-03-01 Call mom +family @phone due:2025-03-20
+x 2025-03-05 2025-03-01 Pay rent +home pri:B
+Water plants t:2025-03-10 rec:+3d id:7
+
+(b) not a priority
+`
+	tasks, err := parseTodoTxt(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, tasks, 4)
+
+	call := tasks[0]
+	require.Equal(t, "Call mom", call.Title)
+	require.Equal(t, "A", call.Priority)
+	require.Equal(t, []string{"family", "phone"}, call.Projects)
+	require.Equal(t, "2025-03-20", call.DueDate.Format("2006-01-02"))
+	require.Equal(t, "2025-03-01", call.Created.Format("2006-01-02"))
+	require.Equal(t, internal.StatusTODO, call.Status)
+
+	rent := tasks[1]
+	require.Equal(t, "Pay rent", rent.Title)
+	require.Equal(t, internal.StatusDONE, rent.Status)
+	require.Equal(t, "B", rent.Priority)
+	require.Equal(t, "2025-03-05", rent.CompletedAt.Format("2006-01-02"))
+
+	plants := tasks[2]
+	require.Equal(t, "Water plants id:7", plants.Title)
+	require.Equal(t, "2025-03-10", plants.ScheduledDate.Format("2006-01-02"))
+	require.Equal(t, "+3d", plants.Recurrence)
+
+	require.Equal(t, "(b) not a priority", tasks[3].Title)
+	require.Empty(t, tasks[3].Priority)
+}
+
+func TestParseTaskwarrior(t *testing.T) {
+	input := `[
+{"uuid":"0d6b8d3e-0f2b-4d6a-9a4e-3f7c1c2d9e01","description":"Write report","status":"pending","entry":"20250301T090000Z","modified":"20250302T090000Z","due":"20250320T230000Z","project":"work","tags":["writing"],"priority":"H","start":"20250302T090000Z","annotations":[{"entry":"20250302T100000Z","description":"Draft is in the wiki"}]},
+{"uuid":"0d6b8d3e-0f2b-4d6a-9a4e-3f7c1c2d9e02","description":"Review report","status":"waiting","wait":"20250325T000000Z","priority":"L","depends":["0d6b8d3e-0f2b-4d6a-9a4e-3f7c1c2d9e01"]},
+{"uuid":"0d6b8d3e-0f2b-4d6a-9a4e-3f7c1c2d9e03","description":"Old task","status":"completed","end":"20250210T120000Z"},
+{"uuid":"0d6b8d3e-0f2b-4d6a-9a4e-3f7c1c2d9e04","description":"Gone","status":"deleted"}
+]`
+	tasks, err := parseTaskwarrior(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+
+	report := tasks[0]
+	require.Equal(t, "0d6b8d3e-0f2b-4d6a-9a4e-3f7c1c2d9e01", report.ID)
+	require.Equal(t, internal.StatusDOING, report.Status)
+	require.Equal(t, "A", report.Priority)
+	require.Equal(t, []string{"work", "writing"}, report.Projects)
+	require.True(t, report.DueDate.Equal(time.Date(2025, 3, 20, 23, 0, 0, 0, time.UTC)))
+	require.True(t, report.Created.Equal(time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)))
+	require.Contains(t, report.Note, "Draft is in the wiki")
+	require.Contains(t, report.Note, "## 2025-03-02(Sun)")
+
+	review := tasks[1]
+	require.Equal(t, "C", review.Priority)
+	require.Equal(t, internal.StatusTODO, review.Status)
+	require.Equal(t, []string{report.ID}, review.BlockedBy)
+	require.True(t, review.ScheduledDate.Equal(time.Date(2025, 3, 25, 0, 0, 0, 0, time.UTC)))
+
+	require.Equal(t, internal.StatusDONE, tasks[2].Status)
+	require.True(t, tasks[2].CompletedAt.Equal(time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)))
+
+	// Older versions export one task per line
+	tasks, err = parseTaskwarrior(strings.NewReader(`{"uuid":"a","description":"One","status":"pending","depends":"b,c"}
+{"uuid":"b","description":"Two","status":"pending"}`))
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	require.Equal(t, []string{"b", "c"}, tasks[0].BlockedBy)
+}
+
+func TestParseTaskCSVRoundTrip(t *testing.T) {
+	due := time.Date(2025, 3, 20, 23, 59, 59, 0, time.Local)
+	task := internal.NewTask("Write, report")
+	task.Projects = []string{"work", "writing"}
+	task.Priority = "B"
+	task.DueDate = &due
+	task.Note = "Line 1\nLine 2"
+	task.EstimateMinutes = 90
+	task.Points = 2.5
+
+	var b strings.Builder
+	require.NoError(t, writeTasksCSV(&b, []internal.Task{*task}))
+
+	tasks, err := parseTaskCSV(strings.NewReader(b.String()))
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Equal(t, task.ID, tasks[0].ID)
+	require.Equal(t, task.Title, tasks[0].Title)
+	require.Equal(t, task.Projects, tasks[0].Projects)
+	require.Equal(t, "B", tasks[0].Priority)
+	require.True(t, due.Equal(*tasks[0].DueDate))
+	require.Equal(t, task.Note, tasks[0].Note)
+	require.Equal(t, 90, tasks[0].EstimateMinutes)
+	require.Equal(t, 2.5, tasks[0].Points)
+
+	// A spreadsheet with only some columns
+	tasks, err = parseTaskCSV(strings.NewReader("Title,Status,Due_Date\nBuy milk,done,2025-03-20\n"))
+	require.NoError(t, err)
+	require.Equal(t, internal.StatusDONE, tasks[0].Status)
+	require.NotNil(t, tasks[0].CompletedAt)
+	require.Equal(t, "2025-03-20", tasks[0].DueDate.Format("2006-01-02"))
+
+	_, err = parseTaskCSV(strings.NewReader("name\nBuy milk\n"))
+	require.Error(t, err)
+	_, err = parseTaskCSV(strings.NewReader("title,status\nBuy milk,later\n"))
+	require.ErrorContains(t, err, "line 2")
+	_, err = parseTaskCSV(strings.NewReader("title,recurrence\nGym,weekly\nBuy milk,sometimes\n"))
+	require.ErrorContains(t, err, "line 3: recurrence")
+}
+
+func TestImportCommand(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	require.NoError(t, taskFile.AddTask(internal.ParseTask("Call mom +family")))
+	path := writeImportFile(t, "todo.txt", "Call mom +family\n(B) Buy milk +home\nPay rent +home\n")
+
+	// A dry run only shows what would be imported
+	output := captureStdout(t, func() {
+		require.NoError(t, ImportCommand(taskFile, []string{"--from", "todotxt", "--dry-run", path}))
+	})
+	require.Contains(t, output, "Would import: TODO Buy milk +home")
+	require.Contains(t, output, "Skipped duplicate: Call mom")
+	require.Contains(t, output, "Would import 2 task(s), 1 duplicate(s) skipped (dry run)")
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	output = captureStdout(t, func() {
+		require.NoError(t, ImportCommand(taskFile, []string{"--from=todotxt", path}))
+	})
+	require.Contains(t, output, "Imported 2 task(s), 1 duplicate(s) skipped")
+	tasks, err = taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+	require.Equal(t, "B", findTaskByTitle(t, tasks, "Buy milk").Priority)
+
+	// Importing the same file again adds nothing
+	output = captureStdout(t, func() {
+		require.NoError(t, ImportCommand(taskFile, []string{"--from", "todotxt", path}))
+	})
+	require.Contains(t, output, "Imported 0 task(s), 3 duplicate(s) skipped")
+
+	require.Error(t, ImportCommand(taskFile, []string{"--from", "jira", path}))
+	require.Error(t, ImportCommand(taskFile, []string{path}))
+}
+
+func TestImportTaskwarriorKeepsDependencies(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	path := writeImportFile(t, "export.json", `[
+{"uuid":"11111111-0000-0000-0000-000000000001","description":"Design","status":"pending"},
+{"uuid":"11111111-0000-0000-0000-000000000002","description":"Build","status":"pending","depends":["11111111-0000-0000-0000-000000000001","11111111-0000-0000-0000-000000000009"]}
+]`)
+
+	captureStdout(t, func() {
+		require.NoError(t, ImportCommand(taskFile, []string{"--from", "taskwarrior", path}))
+	})
+
+	build := findTask(t, taskFile, "11111111-0000-0000-0000-000000000002")
+	// The unknown dependency is dropped, and the open one makes the task wait
+	require.Equal(t, []string{"11111111-0000-0000-0000-000000000001"}, build.BlockedBy)
+	require.Equal(t, internal.StatusWAITING, build.Status)
+}
+
+func TestImportDropsArchivedBlockers(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	blocker := internal.NewTask("Archived blocker")
+	blocker.Status = internal.StatusDONE
+	completedAt := time.Now().AddDate(0, -2, 0)
+	blocker.CompletedAt = &completedAt
+	require.NoError(t, taskFile.AddTask(blocker))
+	_, err := taskFile.ArchiveTasks(time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+
+	path := writeImportFile(t, "tasks.csv", "title,blocked_by\nFollow up,"+blocker.ID+"\n")
+	captureStdout(t, func() {
+		require.NoError(t, ImportCommand(taskFile, []string{"--from", "csv", path}))
+	})
+
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Equal(t, "Follow up", tasks[0].Title)
+	require.Empty(t, tasks[0].BlockedBy)
+	require.Equal(t, internal.StatusTODO, tasks[0].Status)
+}
@@ -7,12 +7,18 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func InteractiveCommandWithFilter(projectFilter string, taskFile *internal.TaskFile) error {
+func InteractiveCommandWithFilter(projectFilter string, viewName string, taskFile *internal.TaskFile) error {
 	model, err := internal.NewInteractiveTaskListWithFilter(taskFile, projectFilter)
 	if err != nil {
 		return fmt.Errorf("failed to create interactive model: %w", err)
 	}
 
+	if viewName != "" {
+		if err := model.SetView(viewName); err != nil {
+			return err
+		}
+	}
+
 	// Start Bubble Tea program with AltScreen
 	p := tea.NewProgram(model, tea.WithAltScreen())
 	if _, err = p.Run(); err != nil {
@@ -2,60 +2,235 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"regexp"
 	"strings"
 	"time"
 
 	"taskeru/internal"
 )
 
+// ListOptions controls how `ls` prints tasks
+type ListOptions struct {
+	ProjectFilter string
+	View          string // Name of a saved view from the config file
+	Query         string // Filter expression, see internal.ParseQuery
+	Sort          string // Sort order, see internal.SortOrders
+	ShowAll       bool   // Include old completed tasks
+	Format        string // text (default), json, jsonl, csv, tsv or template
+	Template      string // Go text/template over internal.Task, used by the template format
+	Color         bool   // Use ANSI colors in the text format
+}
+
+// parseListArgs parses the arguments given after the ls command on top of the global options.
+// Options start with "--" and @name selects a saved view; everything else is the filter expression,
+// so that negated terms like -has:note aren't taken as options.
+func parseListArgs(opts ListOptions, args []string) (ListOptions, error) {
+	opts.Format = "text"
+	opts.Color = isColorTerminal(os.Stdout)
+
+	var queryArgs []string
+	for i := 0; i < len(args); i++ {
+		if strings.HasPrefix(args[i], "@") && len(args[i]) > 1 {
+			opts.View = args[i][1:]
+			continue
+		}
+		if args[i] == "-a" || args[i] == "--all" {
+			opts.ShowAll = true
+			continue
+		}
+		if !strings.HasPrefix(args[i], "--") {
+			queryArgs = append(queryArgs, args[i])
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(args[i], "--"), "=")
+		if !hasValue {
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("missing value for --%s", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch name {
+		case "format":
+			opts.Format = value
+		case "template":
+			opts.Template = value
+		default:
+			return opts, fmt.Errorf("unknown ls option: --%s", name)
+		}
+	}
+	opts.Query = strings.Join(queryArgs, " ")
+
+	// --template alone implies the template format
+	if opts.Template != "" && opts.Format == "text" {
+		opts.Format = "template"
+	}
+	return opts, nil
+}
+
+// isColorTerminal reports whether ANSI colors should be written to f.
+// Colors are disabled when f is not a terminal or NO_COLOR is set.
+func isColorTerminal(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+var ansiEscapeRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// resolveView merges the saved view named by opts.View into the options.
+// The filter of the view is combined with the filter given on the command line.
+func resolveView(opts ListOptions) (ListOptions, error) {
+	if opts.View == "" {
+		return opts, nil
+	}
+
+	config, err := internal.LoadConfig()
+	if err != nil {
+		return opts, fmt.Errorf("failed to load config: %w", err)
+	}
+	view, err := config.GetView(opts.View)
+	if err != nil {
+		return opts, err
+	}
+
+	opts.Query = strings.TrimSpace(view.Filter + " " + opts.Query)
+	if opts.Sort == "" {
+		opts.Sort = view.Sort
+	}
+	opts.ShowAll = opts.ShowAll || view.ShowAll
+	return opts, nil
+}
+
+// listedTasks returns tasks in the order `ls` prints them, so that list indexes can address tasks.
+// The filter expression doesn't change the indexes, so it is not applied here.
+func listedTasks(tasks []internal.Task, opts ListOptions) []internal.Task {
+	if opts.ProjectFilter != "" {
+		tasks = internal.FilterTasksByProject(tasks, opts.ProjectFilter)
+	}
+	internal.SortTasksBy(tasks, opts.Sort)
+	return internal.FilterVisibleTasks(tasks, opts.ShowAll)
+}
+
+// loadListTasks loads the tasks `ls` works on. Showing all tasks includes the archived ones.
+func loadListTasks(taskFile *internal.TaskFile, opts ListOptions) ([]internal.Task, error) {
+	if opts.ShowAll {
+		return taskFile.LoadTasksWithArchive()
+	}
+	return taskFile.LoadTasks()
+}
+
 func ListCommand(taskFile *internal.TaskFile, projectFilter string) error {
-	tasks, err := taskFile.LoadTasks()
+	return ListCommandWithOptions(taskFile, ListOptions{
+		ProjectFilter: projectFilter,
+		Color:         isColorTerminal(os.Stdout),
+	})
+}
+
+func ListCommandWithOptions(taskFile *internal.TaskFile, opts ListOptions) error {
+	opts, err := resolveView(opts)
+	if err != nil {
+		return err
+	}
+
+	query, err := internal.ParseQuery(opts.Query)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := loadListTasks(taskFile, opts)
 	if err != nil {
 		return fmt.Errorf("failed to load tasks: %w", err)
 	}
 
+	if opts.Format != "" && opts.Format != "text" {
+		listed := internal.FilterTasksByQuery(listedTasks(tasks, opts), query)
+		return writeTasks(os.Stdout, listed, opts.Format, opts.Template)
+	}
+
+	var w strings.Builder
+	writeTaskList(&w, tasks, opts, query)
+
+	output := w.String()
+	if !opts.Color {
+		output = ansiEscapeRegex.ReplaceAllString(output, "")
+	}
+	fmt.Print(output)
+	return nil
+}
+
+// writeTaskList writes the human readable task list.
+// Tasks not matching the query are skipped but keep their list index,
+// so the printed numbers can be passed to commands like `taskeru done`.
+func writeTaskList(w *strings.Builder, tasks []internal.Task, opts ListOptions, query *internal.Query) {
+	projectFilter := opts.ProjectFilter
+
+	// Count blockers before filtering, as blockers may belong to other projects
+	openBlockers := internal.CountOpenBlockers(tasks)
+
 	// Filter by project if specified
 	if projectFilter != "" {
 		tasks = internal.FilterTasksByProject(tasks, projectFilter)
 	}
 
-	// Sort tasks by priority and update time
-	internal.SortTasks(tasks)
+	// Sort tasks by priority and update time, or by the order of the view
+	internal.SortTasksBy(tasks, opts.Sort)
 
 	// Filter out old completed tasks by default
-	visibleTasks := internal.FilterVisibleTasks(tasks, false)
+	visibleTasks := internal.FilterVisibleTasks(tasks, opts.ShowAll)
 
-	if len(visibleTasks) == 0 {
-		if projectFilter != "" {
-			fmt.Printf("No tasks found for project: %s\n", projectFilter)
+	if opts.View != "" {
+		fmt.Fprintf(w, "View: @%s\n", opts.View)
+	}
+	matchCount := len(internal.FilterTasksByQuery(visibleTasks, query))
+
+	if matchCount == 0 {
+		if !query.IsEmpty() {
+			fmt.Fprintln(w, "No tasks match the query.")
+		} else if projectFilter != "" {
+			fmt.Fprintf(w, "No tasks found for project: %s\n", projectFilter)
 		} else {
-			fmt.Println("No tasks found.")
+			fmt.Fprintln(w, "No tasks found.")
 		}
 		hiddenCount := len(tasks) - len(visibleTasks)
 		if hiddenCount > 0 {
-			fmt.Printf("(%d old completed tasks hidden)\n", hiddenCount)
+			fmt.Fprintf(w, "(%d old completed tasks hidden)\n", hiddenCount)
 		}
-		return nil
+		return
 	}
 
 	if projectFilter != "" {
 		// Show project with color and count
 		projectColor := internal.GetProjectColor(projectFilter)
-		fmt.Printf("Tasks for project: %s+%s\x1b[0m (%d task", projectColor, projectFilter, len(visibleTasks))
-		if len(visibleTasks) != 1 {
-			fmt.Print("s")
+		fmt.Fprintf(w, "Tasks for project: %s+%s\x1b[0m (%d task", projectColor, projectFilter, matchCount)
+		if matchCount != 1 {
+			fmt.Fprint(w, "s")
 		}
 		hiddenCount := len(tasks) - len(visibleTasks)
 		if hiddenCount > 0 {
-			fmt.Printf(", %d hidden", hiddenCount)
+			fmt.Fprintf(w, ", %d hidden", hiddenCount)
 		}
-		fmt.Println(")")
+		fmt.Fprintln(w, ")")
 	} else {
-		fmt.Println("Tasks:")
+		fmt.Fprintln(w, "Tasks:")
 	}
-	fmt.Println("------")
+	fmt.Fprintln(w, "------")
+
+	depths := internal.TaskDepths(visibleTasks)
 
 	for i, task := range visibleTasks {
+		if !query.Match(&task) {
+			continue
+		}
+
 		status := task.DisplayStatus()
 		priority := task.DisplayPriority()
 
@@ -74,7 +249,9 @@ func ListCommand(taskFile *internal.TaskFile, projectFilter string) error {
 			statusColor = "\x1b[37m" // white
 		}
 
-		fmt.Printf("%d. %s%-7s %s %s\x1b[0m", i+1, statusColor, status, priority, task.Title)
+		// Indent subtasks under their parent
+		indent := strings.Repeat("  ", depths[task.ID])
+		fmt.Fprintf(w, "%d. %s%-7s %s %s%s\x1b[0m", i+1, statusColor, status, priority, indent, task.Title)
 
 		// Display projects with colors
 		if len(task.Projects) > 0 {
@@ -84,7 +261,7 @@ func ListCommand(taskFile *internal.TaskFile, projectFilter string) error {
 				color := internal.GetProjectColor(project)
 				projectStrs = append(projectStrs, fmt.Sprintf("%s+%s\x1b[0m", color, project))
 			}
-			fmt.Printf(" %s", strings.Join(projectStrs, " "))
+			fmt.Fprintf(w, " %s", strings.Join(projectStrs, " "))
 		}
 
 		// Display scheduled date if future
@@ -92,46 +269,58 @@ func ListCommand(taskFile *internal.TaskFile, projectFilter string) error {
 			schedIn := time.Until(*task.ScheduledDate)
 			if schedIn < 24*time.Hour {
 				// Starts tomorrow - green
-				fmt.Printf(" \x1b[32m(starts tomorrow)\x1b[0m")
+				fmt.Fprintf(w, " \x1b[32m(starts tomorrow)\x1b[0m")
 			} else if schedIn < 7*24*time.Hour {
 				// Starts this week - dim green
-				fmt.Printf(" \x1b[92m(starts %s)\x1b[0m", task.ScheduledDate.Format("Mon"))
+				fmt.Fprintf(w, " \x1b[92m(starts %s)\x1b[0m", task.ScheduledDate.Format("Mon"))
 			} else {
 				// Starts later - dim
-				fmt.Printf(" \x1b[90m(starts %s)\x1b[0m", task.ScheduledDate.Format("01-02"))
+				fmt.Fprintf(w, " \x1b[90m(starts %s)\x1b[0m", task.ScheduledDate.Format("01-02"))
 			}
 		}
 
 		// Display completion date for done/wontdo tasks (dim gray)
 		if (task.Status == internal.StatusDONE || task.Status == internal.StatusWONTDO) && task.CompletedAt != nil {
 			// Use dim gray color (ANSI 90) for completed date
-			fmt.Printf(" \x1b[90m(completed %s)\x1b[0m", task.CompletedAt.Format("2006-01-02"))
+			fmt.Fprintf(w, " \x1b[90m(completed %s)\x1b[0m", task.CompletedAt.Format("2006-01-02"))
 		} else if task.DueDate != nil {
 			dueIn := time.Until(*task.DueDate)
 			if dueIn < 0 {
 				// Overdue - red
-				fmt.Printf(" \x1b[91m(overdue %s)\x1b[0m", task.DueDate.Format("01-02"))
+				fmt.Fprintf(w, " \x1b[91m(overdue %s)\x1b[0m", task.DueDate.Format("01-02"))
 			} else if dueIn < 24*time.Hour {
 				// Due today - yellow
-				fmt.Printf(" \x1b[93m(due today)\x1b[0m")
+				fmt.Fprintf(w, " \x1b[93m(due today)\x1b[0m")
 			} else if dueIn < 48*time.Hour {
 				// Due tomorrow - light yellow
-				fmt.Printf(" \x1b[33m(due tomorrow)\x1b[0m")
+				fmt.Fprintf(w, " \x1b[33m(due tomorrow)\x1b[0m")
 			} else if dueIn < 7*24*time.Hour {
 				// Due this week - cyan
-				fmt.Printf(" \x1b[36m(due %s)\x1b[0m", task.DueDate.Format("Mon"))
+				fmt.Fprintf(w, " \x1b[36m(due %s)\x1b[0m", task.DueDate.Format("Mon"))
 			} else {
 				// Due later - dim
-				fmt.Printf(" \x1b[90m(due %s)\x1b[0m", task.DueDate.Format("01-02"))
+				fmt.Fprintf(w, " \x1b[90m(due %s)\x1b[0m", task.DueDate.Format("01-02"))
 			}
 		}
 
-		fmt.Println()
+		if task.Recurrence != "" {
+			fmt.Fprintf(w, " \x1b[90m(repeat %s)\x1b[0m", task.Recurrence)
+		}
+
+		if effort := task.DisplayEffort(); effort != "" {
+			fmt.Fprintf(w, " \x1b[90m[%s]\x1b[0m", effort)
+		}
+
+		if count := openBlockers[task.ID]; count > 0 {
+			fmt.Fprintf(w, " \x1b[35m(blocked by %d)\x1b[0m", count)
+		}
+
+		fmt.Fprintln(w)
 
 		if task.Note != "" {
 			lines := getFirstNLines(task.Note, 1)
 			if len(lines) > 0 && lines[0] != "" {
-				fmt.Printf("   └─ %s\n", lines[0])
+				fmt.Fprintf(w, "   └─ %s\n", lines[0])
 			}
 		}
 	}
@@ -140,11 +329,9 @@ func ListCommand(taskFile *internal.TaskFile, projectFilter string) error {
 	if projectFilter == "" {
 		hiddenCount := len(tasks) - len(visibleTasks)
 		if hiddenCount > 0 {
-			fmt.Printf("\n(%d old completed tasks hidden)\n", hiddenCount)
+			fmt.Fprintf(w, "\n(%d old completed tasks hidden)\n", hiddenCount)
 		}
 	}
-
-	return nil
 }
 
 func getFirstNLines(text string, n int) []string {
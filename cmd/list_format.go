@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"taskeru/internal"
+)
+
+// taskColumns are the columns of the csv/tsv formats, named after the JSON fields of internal.Task
+var taskColumns = []string{
+	"id", "title", "status", "priority", "projects",
+	"due_date", "scheduled_date", "completed_at", "created", "updated",
+	"recurrence", "parent_id", "blocked_by", "note", "estimate_minutes", "points",
+}
+
+// writeTasks writes tasks in a machine readable format.
+// The json and jsonl formats use the same schema as the task file.
+func writeTasks(out io.Writer, tasks []internal.Task, format string, tmpl string) error {
+	switch format {
+	case "json":
+		if tasks == nil {
+			tasks = []internal.Task{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(tasks)
+	case "jsonl":
+		encoder := json.NewEncoder(out)
+		for _, task := range tasks {
+			if err := encoder.Encode(task); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		return writeTasksCSV(out, tasks)
+	case "tsv":
+		return writeTasksTSV(out, tasks)
+	case "template":
+		return writeTasksTemplate(out, tasks, tmpl)
+	default:
+		return fmt.Errorf("unknown format: %s (expected text, json, jsonl, csv, tsv or template)", format)
+	}
+}
+
+func writeTasksCSV(out io.Writer, tasks []internal.Task) error {
+	writer := csv.NewWriter(out)
+	if err := writer.Write(taskColumns); err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if err := writer.Write(taskRecord(task)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeTasksTSV writes one task per line. Tabs and newlines in values are escaped,
+// so that each line can be processed with cut/awk.
+func writeTasksTSV(out io.Writer, tasks []internal.Task) error {
+	escaper := strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+	var b strings.Builder
+	b.WriteString(strings.Join(taskColumns, "\t"))
+	b.WriteString("\n")
+	for _, task := range tasks {
+		record := taskRecord(task)
+		for i, value := range record {
+			record[i] = escaper.Replace(value)
+		}
+		b.WriteString(strings.Join(record, "\t"))
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// taskRecord returns the values of taskColumns for a task
+func taskRecord(task internal.Task) []string {
+	return []string{
+		task.ID,
+		task.Title,
+		task.Status,
+		task.Priority,
+		strings.Join(task.Projects, ","),
+		formatTime(task.DueDate),
+		formatTime(task.ScheduledDate),
+		formatTime(task.CompletedAt),
+		task.Created.Format(time.RFC3339),
+		task.Updated.Format(time.RFC3339),
+		task.Recurrence,
+		task.ParentID,
+		strings.Join(task.BlockedBy, ","),
+		task.Note,
+		formatNumber(float64(task.EstimateMinutes)),
+		formatNumber(task.Points),
+	}
+}
+
+// writeTasksTemplate executes a Go text/template for each task, followed by a newline
+func writeTasksTemplate(out io.Writer, tasks []internal.Task, text string) error {
+	if text == "" {
+		return fmt.Errorf("--template is required for the template format")
+	}
+
+	tmpl, err := template.New("task").Funcs(template.FuncMap{
+		"join": strings.Join,
+		"date": func(layout string, t *time.Time) string {
+			if t == nil {
+				return ""
+			}
+			return t.Format(layout)
+		},
+	}).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	for _, task := range tasks {
+		if err := tmpl.Execute(out, task); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		if _, err := io.WriteString(out, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatNumber formats an optional number, which is empty when not set
+func formatNumber(n float64) string {
+	if n == 0 {
+		return ""
+	}
+	return internal.FormatPoints(n)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"taskeru/internal"
+)
+
+func formatTestTasks() []internal.Task {
+	due := time.Date(2025, 1, 3, 23, 59, 59, 0, time.UTC)
+
+	first := internal.NewTask("Write report")
+	first.Projects = []string{"work", "docs"}
+	first.DueDate = &due
+	first.Priority = "A"
+
+	second := internal.NewTask("Buy milk")
+	second.Note = "line1\tcol\nline2"
+
+	return []internal.Task{*first, *second}
+}
+
+func TestWriteTasksJSON(t *testing.T) {
+	tasks := formatTestTasks()
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTasks(&buf, tasks, "json", ""))
+
+	var decoded []internal.Task
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Len(t, decoded, 2)
+	require.Equal(t, tasks[0].ID, decoded[0].ID)
+	require.Equal(t, []string{"work", "docs"}, decoded[0].Projects)
+	require.True(t, tasks[0].DueDate.Equal(*decoded[0].DueDate))
+
+	// Same field names as the task file
+	require.Contains(t, buf.String(), `"due_date"`)
+
+	// No tasks is an empty array, not null
+	buf.Reset()
+	require.NoError(t, writeTasks(&buf, nil, "json", ""))
+	require.Equal(t, "[]\n", buf.String())
+}
+
+func TestWriteTasksJSONL(t *testing.T) {
+	tasks := formatTestTasks()
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTasks(&buf, tasks, "jsonl", ""))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	for i, line := range lines {
+		var task internal.Task
+		require.NoError(t, json.Unmarshal([]byte(line), &task))
+		require.Equal(t, tasks[i].ID, task.ID)
+	}
+}
+
+func TestWriteTasksCSV(t *testing.T) {
+	tasks := formatTestTasks()
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTasks(&buf, tasks, "csv", ""))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, taskColumns, records[0])
+	require.Equal(t, "Write report", records[1][1])
+	require.Equal(t, "work,docs", records[1][4])
+	require.Equal(t, "2025-01-03T23:59:59Z", records[1][5])
+	require.Equal(t, "line1\tcol\nline2", records[2][13])
+}
+
+func TestWriteTasksCSVRoundTrip(t *testing.T) {
+	task := internal.NewTask("Estimate me")
+	task.EstimateMinutes = 90
+	task.Points = 0.5
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTasks(&buf, []internal.Task{*task, *internal.NewTask("No effort")}, "csv", ""))
+
+	records, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	require.NoError(t, err)
+	require.Contains(t, records[0], "estimate_minutes")
+	require.Contains(t, records[0], "points")
+
+	// Every column comes back with `taskeru import --from csv`
+	imported, err := parseTaskCSV(&buf)
+	require.NoError(t, err)
+	require.Len(t, imported, 2)
+	require.Equal(t, 90, imported[0].EstimateMinutes)
+	require.Equal(t, 0.5, imported[0].Points)
+	require.Zero(t, imported[1].EstimateMinutes)
+	require.Zero(t, imported[1].Points)
+}
+
+func TestTaskColumnsCoverTaskFields(t *testing.T) {
+	// JSON fields of the task file which the csv/tsv formats leave out on purpose
+	excluded := map[string]string{
+		"time_log":      "a list of timer entries, kept by the json and jsonl formats",
+		"deleted_at":    "only set on tasks in the trash file",
+		"recurred_from": "only used to take back an occurrence when its task is reopened",
+	}
+
+	taskType := reflect.TypeOf(internal.Task{})
+	for i := 0; i < taskType.NumField(); i++ {
+		name, _, _ := strings.Cut(taskType.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if _, ok := excluded[name]; ok {
+			require.NotContains(t, taskColumns, name)
+			continue
+		}
+		require.Contains(t, taskColumns, name, "add %s to taskColumns and taskRecord, or exclude it here", name)
+	}
+	require.Len(t, taskRecord(internal.Task{}), len(taskColumns))
+}
+
+func TestWriteTasksTSV(t *testing.T) {
+	tasks := formatTestTasks()
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTasks(&buf, tasks, "tsv", ""))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3, "tabs and newlines in values must be escaped")
+	require.Equal(t, strings.Join(taskColumns, "\t"), lines[0])
+
+	fields := strings.Split(lines[2], "\t")
+	require.Len(t, fields, len(taskColumns))
+	require.Equal(t, `line1\tcol\nline2`, fields[13])
+}
+
+func TestWriteTasksTemplate(t *testing.T) {
+	tasks := formatTestTasks()
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTasks(&buf, tasks, "template",
+		`{{.Title}}|{{join .Projects ","}}|{{date "2006-01-02" .DueDate}}`))
+	require.Equal(t, "Write report|work,docs|2025-01-03\nBuy milk||\n", buf.String())
+
+	require.Error(t, writeTasks(&buf, tasks, "template", ""))
+	require.Error(t, writeTasks(&buf, tasks, "template", "{{.Title"))
+	require.Error(t, writeTasks(&buf, tasks, "yaml", ""))
+}
+
+func TestParseListArgs(t *testing.T) {
+	opts, err := parseListArgs(ListOptions{ProjectFilter: "work"}, []string{"--format", "csv"})
+	require.NoError(t, err)
+	require.Equal(t, "work", opts.ProjectFilter)
+	require.Equal(t, "csv", opts.Format)
+
+	opts, err = parseListArgs(ListOptions{}, []string{"--template", "{{.ID}}"})
+	require.NoError(t, err)
+	require.Equal(t, "template", opts.Format)
+
+	opts, err = parseListArgs(ListOptions{}, []string{"status:DOING", "-has:note", "--format=jsonl", "+work"})
+	require.NoError(t, err)
+	require.Equal(t, "jsonl", opts.Format)
+	require.Equal(t, "status:DOING -has:note +work", opts.Query)
+
+	_, err = parseListArgs(ListOptions{}, []string{"--unknown", "x"})
+	require.Error(t, err)
+
+	_, err = parseListArgs(ListOptions{}, []string{"--format"})
+	require.Error(t, err)
+}
+
+func TestListCommandWithoutColor(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	task := internal.NewTask("Plain task +work")
+	require.NoError(t, taskFile.AddTask(task))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, ListCommandWithOptions(taskFile, ListOptions{Color: false}))
+	})
+	require.Contains(t, output, "Plain task +work")
+	require.NotContains(t, output, "\x1b[")
+
+	output = captureStdout(t, func() {
+		require.NoError(t, ListCommandWithOptions(taskFile, ListOptions{Color: true}))
+	})
+	require.Contains(t, output, "\x1b[")
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"taskeru/internal"
@@ -207,3 +208,154 @@ func TestListCommandWithCompletedTasksAndProjectFilter(t *testing.T) {
 func contains(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0 && bytes.Contains([]byte(s), []byte(substr))
 }
+
+// captureStdout runs fn and returns everything it printed to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	fn()
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	return buf.String()
+}
+
+func TestListCommandShowsBlockedTasks(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+
+	blocker := internal.NewTask("Blocker task +other")
+	blocked := internal.NewTask("Blocked task")
+	blocked.Projects = []string{"work"}
+	blocked.BlockedBy = []string{blocker.ID}
+	if err := taskFile.AddTasks([]internal.Task{*blocker, *blocked}); err != nil {
+		t.Fatalf("Failed to save test tasks: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		if err := ListCommand(taskFile, "work"); err != nil {
+			t.Errorf("ListCommand() error = %v", err)
+		}
+	})
+
+	if !contains(output, "WAITING") || !contains(output, "(blocked by 1)") {
+		t.Errorf("Expected blocked task to be shown as blocked\nActual output:\n%s", output)
+	}
+}
+
+func TestListCommandShowsEffort(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	if err := taskFile.AddTask(internal.ParseTask("Write report est:2h pts:3")); err != nil {
+		t.Fatalf("Failed to save test tasks: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		if err := ListCommand(taskFile, ""); err != nil {
+			t.Errorf("ListCommand() error = %v", err)
+		}
+	})
+
+	if !contains(output, "Write report") || !contains(output, "[est:2h pts:3]") {
+		t.Errorf("Expected estimate and points in the list\nActual output:\n%s", output)
+	}
+}
+
+func TestListCommandWithQueryKeepsListIndexes(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+
+	first := internal.NewTask("First task")
+	first.Priority = "A"
+	second := internal.NewTask("Second task")
+	second.Priority = "B"
+	if err := taskFile.AddTasks([]internal.Task{*first, *second}); err != nil {
+		t.Fatalf("Failed to save test tasks: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		if err := ListCommandWithOptions(taskFile, ListOptions{Query: "prio:B"}); err != nil {
+			t.Errorf("ListCommandWithOptions() error = %v", err)
+		}
+	})
+
+	if contains(output, "First task") || !contains(output, "2. TODO") {
+		t.Errorf("Expected only the second task with its ls index\nActual output:\n%s", output)
+	}
+
+	output = captureStdout(t, func() {
+		if err := ListCommandWithOptions(taskFile, ListOptions{Query: "nothing-matches"}); err != nil {
+			t.Errorf("ListCommandWithOptions() error = %v", err)
+		}
+	})
+	if !contains(output, "No tasks match the query.") {
+		t.Errorf("Expected no match message\nActual output:\n%s", output)
+	}
+}
+
+func TestListCommandWithView(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("HOME", configHome)
+	configPath, err := internal.UserConfigPath()
+	if err != nil {
+		t.Fatalf("Failed to get config path: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	config := `
+[views.mine]
+filter = "+work"
+sort = "title"
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	taskFile := internal.NewTaskFileForTesting(t)
+	if err := taskFile.AddTasks([]internal.Task{
+		*internal.ParseTask("Zebra task +work"),
+		*internal.ParseTask("Apple task +work"),
+		*internal.ParseTask("Home task +home"),
+	}); err != nil {
+		t.Fatalf("Failed to save test tasks: %v", err)
+	}
+
+	opts, err := parseListArgs(ListOptions{}, []string{"@mine"})
+	if err != nil {
+		t.Fatalf("parseListArgs() error = %v", err)
+	}
+	output := captureStdout(t, func() {
+		if err := ListCommandWithOptions(taskFile, opts); err != nil {
+			t.Errorf("ListCommandWithOptions() error = %v", err)
+		}
+	})
+
+	if !contains(output, "View: @mine") || contains(output, "Home task") {
+		t.Errorf("Expected only tasks of the view\nActual output:\n%s", output)
+	}
+	// Hidden tasks keep their index ("Home task" is 2)
+	if !contains(output, "1. TODO        Apple task") || !contains(output, "3. TODO        Zebra task") {
+		t.Errorf("Expected tasks sorted by title\nActual output:\n%s", output)
+	}
+
+	// List indexes of -v follow the order of the view
+	if err := StatusCommand(taskFile, ListOptions{View: "mine"}, internal.StatusDONE, []string{"1"}); err != nil {
+		t.Fatalf("StatusCommand() error = %v", err)
+	}
+	tasks, _ := taskFile.LoadTasks()
+	for _, task := range tasks {
+		if (task.Title == "Apple task") != (task.Status == internal.StatusDONE) {
+			t.Errorf("Unexpected status %s for %q", task.Status, task.Title)
+		}
+	}
+
+	if err := ListCommandWithOptions(taskFile, ListOptions{View: "missing"}); err == nil {
+		t.Error("Expected error for unknown view")
+	}
+}
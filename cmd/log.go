@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"taskeru/internal"
+)
+
+// journalActor names who makes the changes of this run in the change journal, e.g. "alice (tui)"
+func journalActor(args []string) string {
+	source := "tui"
+	if len(args) > 0 {
+		switch args[0] {
+		case "httpd":
+			source = "web"
+		case "sync":
+			source = "sync"
+		default:
+			source = "cli"
+		}
+	}
+	return fmt.Sprintf("%s (%s)", internal.DefaultActor(), source)
+}
+
+// describeJournalEntry returns what an entry changed, e.g. `priority: (none) → A`
+func describeJournalEntry(entry internal.JournalEntry) string {
+	switch entry.Field {
+	case internal.JournalCreated, internal.JournalRestored:
+		return fmt.Sprintf("%s %q", entry.Field, internal.FormatJournalValue(entry.New))
+	case internal.JournalDeleted, internal.JournalArchived:
+		return fmt.Sprintf("%s %q", entry.Field, internal.FormatJournalValue(entry.Old))
+	}
+	return fmt.Sprintf("%s: %s → %s", entry.Field,
+		shortJournalValue(internal.FormatJournalValue(entry.Old)), shortJournalValue(internal.FormatJournalValue(entry.New)))
+}
+
+// shortJournalValue keeps values such as notes on one line
+func shortJournalValue(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if runes := []rune(value); len(runes) > 60 {
+		value = string(runes[:59]) + "…"
+	}
+	return value
+}
+
+// LogCommand prints the change journal of a task: taskeru log <id>
+// Besides the tasks `ls` shows, deleted and archived tasks can be given by ID prefix.
+func LogCommand(taskFile *internal.TaskFile, listOpts ListOptions, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: log <id>")
+	}
+
+	entries, err := taskFile.LoadJournal("")
+	if err != nil {
+		return err
+	}
+
+	var taskID, title string
+	if tasks, err := loadAndResolveListed(taskFile, listOpts, args); err == nil {
+		taskID, title = tasks[0].ID, tasks[0].Title
+	} else {
+		// The task may be gone, but its changes are still in the journal
+		var journaled []internal.Task
+		seen := make(map[string]bool)
+		for _, entry := range entries {
+			if !seen[entry.TaskID] {
+				seen[entry.TaskID] = true
+				journaled = append(journaled, internal.Task{ID: entry.TaskID})
+			}
+		}
+		if taskID, err = internal.ResolveTaskID(journaled, args[0]); err != nil {
+			return err
+		}
+	}
+
+	var history []internal.JournalEntry
+	for _, entry := range entries {
+		if entry.TaskID == taskID {
+			history = append(history, entry)
+		}
+	}
+	if title == "" {
+		for _, entry := range history {
+			if entry.Field == internal.JournalDeleted || entry.Field == internal.JournalArchived {
+				title = internal.FormatJournalValue(entry.Old)
+			}
+		}
+	}
+
+	return writeJournal(os.Stdout, title, taskID, history)
+}
+
+func writeJournal(out io.Writer, title, taskID string, entries []internal.JournalEntry) error {
+	var w strings.Builder
+	fmt.Fprintf(&w, "%s \x1b[90m(%s)\x1b[0m\n", title, taskID)
+	fmt.Fprintln(&w, "------")
+	if len(entries) == 0 {
+		fmt.Fprintln(&w, "No changes recorded.")
+	}
+	for _, entry := range entries {
+		fmt.Fprintf(&w, "%s  \x1b[90m%s\x1b[0m  %s\n",
+			entry.Time.Local().Format("2006-01-02 15:04"), entry.Actor, describeJournalEntry(entry))
+	}
+
+	output := w.String()
+	if file, ok := out.(*os.File); !ok || !isColorTerminal(file) {
+		output = ansiEscapeRegex.ReplaceAllString(output, "")
+	}
+	_, err := io.WriteString(out, output)
+	return err
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"taskeru/internal"
+)
+
+func TestLogCommand(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	taskFile.Actor = "alice (cli)"
+	task := internal.NewTask("Write report")
+	require.NoError(t, taskFile.AddTask(task))
+	captureStdout(t, func() {
+		require.NoError(t, PriorityCommand(taskFile, ListOptions{}, []string{"1", "A"}))
+		require.NoError(t, NoteCommand(taskFile, ListOptions{}, []string{"1", "first draft"}))
+	})
+
+	output := captureStdout(t, func() {
+		require.NoError(t, LogCommand(taskFile, ListOptions{}, []string{"1"}))
+	})
+	require.Contains(t, output, "Write report ("+task.ID+")")
+	require.Contains(t, output, `alice (cli)  created "Write report"`)
+	require.Contains(t, output, "alice (cli)  priority: (none) → A")
+	require.Contains(t, output, "note: (none) → ")
+	require.Less(t, strings.Index(output, "created"), strings.Index(output, "priority"))
+
+	// Deleted tasks are found in the journal by ID prefix
+	require.NoError(t, taskFile.DeleteTask(task.ID))
+	output = captureStdout(t, func() {
+		require.NoError(t, LogCommand(taskFile, ListOptions{}, []string{task.ID[:len(task.ID)-4]}))
+	})
+	require.Contains(t, output, "Write report ("+task.ID+")")
+	require.Contains(t, output, `deleted "Write report"`)
+
+	require.Error(t, LogCommand(taskFile, ListOptions{}, []string{"ffff"}))
+	require.Error(t, LogCommand(taskFile, ListOptions{}, nil))
+}
+
+func TestJournalActor(t *testing.T) {
+	user := internal.DefaultActor()
+	require.Equal(t, user+" (tui)", journalActor(nil))
+	require.Equal(t, user+" (web)", journalActor([]string{"httpd", ":7676"}))
+	require.Equal(t, user+" (cli)", journalActor([]string{"done", "1"}))
+	require.Equal(t, user+" (sync)", journalActor([]string{"sync"}))
+}
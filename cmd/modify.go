@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"taskeru/internal"
+)
+
+// resolveTaskRefs finds the tasks referenced by list index (as printed by `ls`) or by unique ID prefix.
+// Numbers starting with 0 are treated as ID prefixes, since task IDs are UUIDv7 starting with 0.
+// The task picked by a list index is printed to stderr, as `ls` may have shown another list.
+func resolveTaskRefs(tasks []internal.Task, opts ListOptions, refs []string) ([]internal.Task, error) {
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("task ID or list index is required")
+	}
+
+	listed := listedTasks(slices.Clone(tasks), opts)
+
+	var resolved []internal.Task
+	for _, ref := range refs {
+		if index, err := strconv.Atoi(ref); err == nil && !strings.HasPrefix(ref, "0") {
+			if index < 1 || index > len(listed) {
+				return nil, fmt.Errorf("list index %d is out of range (1-%d)", index, len(listed))
+			}
+			task := listed[index-1]
+			_, _ = fmt.Fprintf(os.Stderr, "%d: %s (%s)\n", index, task.Title, task.ID)
+			resolved = append(resolved, task)
+			continue
+		}
+
+		id, err := internal.ResolveTaskID(tasks, ref)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			if task.ID == id {
+				resolved = append(resolved, task)
+				break
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// updateTasks applies updateFunc to each task, merged with changes other processes made meanwhile
+func updateTasks(taskFile *internal.TaskFile, tasks []internal.Task, updateFunc func(*internal.Task)) error {
+	for _, task := range tasks {
+		if err := taskFile.UpdateTaskWithMerge(task, updateFunc); err != nil {
+			return saveError(err)
+		}
+		fmt.Printf("Task updated: %s\n", task.Title)
+	}
+	return nil
+}
+
+// saveError explains a failed update, naming the fields another process changed too
+func saveError(err error) error {
+	var conflict *internal.ConflictError
+	if errors.As(err, &conflict) {
+		return fmt.Errorf("conflict: %s of %q was also modified by another process, please try again",
+			strings.Join(conflict.Fields, ", "), conflict.Current.Title)
+	}
+	return fmt.Errorf("failed to save task: %w", err)
+}
+
+// loadAndResolve loads all tasks and resolves the references of tasks to change.
+// List indexes follow `ls` with the same -p and -v options, so a view showing all tasks
+// may list archived tasks, which can't be changed.
+func loadAndResolve(taskFile *internal.TaskFile, opts ListOptions, refs []string) ([]internal.Task, error) {
+	resolved, err := loadAndResolveListed(taskFile, opts, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := taskFile.LoadTasks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+	for _, task := range resolved {
+		if !slices.ContainsFunc(current, func(t internal.Task) bool { return t.ID == task.ID }) {
+			return nil, fmt.Errorf("task is archived: %s (%s)", task.Title, task.ID)
+		}
+	}
+	return resolved, nil
+}
+
+// loadAndResolveListed resolves task references like loadAndResolve, including archived tasks
+func loadAndResolveListed(taskFile *internal.TaskFile, opts ListOptions, refs []string) ([]internal.Task, error) {
+	opts, err := resolveView(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := loadListTasks(taskFile, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+	return resolveTaskRefs(tasks, opts, refs)
+}
+
+// StatusCommand changes the status of one or more tasks
+func StatusCommand(taskFile *internal.TaskFile, listOpts ListOptions, status string, args []string) error {
+	tasks, err := loadAndResolve(taskFile, listOpts, args)
+	if err != nil {
+		return err
+	}
+
+	return updateTasks(taskFile, tasks, func(t *internal.Task) {
+		t.SetStatus(status)
+	})
+}
+
+// StopCommand stops the timers of DOING tasks by moving them back to TODO
+func StopCommand(taskFile *internal.TaskFile, listOpts ListOptions, args []string) error {
+	tasks, err := loadAndResolve(taskFile, listOpts, args)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if task.Status != internal.StatusDOING {
+			return fmt.Errorf("timer is not running: %s", task.Title)
+		}
+	}
+	return updateTasks(taskFile, tasks, func(t *internal.Task) {
+		t.SetStatus(internal.StatusTODO)
+	})
+}
+
+// RemoveCommand deletes one or more tasks (they are kept in the trash file)
+func RemoveCommand(taskFile *internal.TaskFile, listOpts ListOptions, args []string) error {
+	tasks, err := loadAndResolve(taskFile, listOpts, args)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if err := taskFile.DeleteTask(task.ID); err != nil {
+			return fmt.Errorf("failed to delete task: %w", err)
+		}
+		fmt.Printf("Task deleted: %s\n", task.Title)
+	}
+	return nil
+}
+
+// PriorityCommand sets the priority of a task: taskeru prio <id> <A-Z|none>
+func PriorityCommand(taskFile *internal.TaskFile, listOpts ListOptions, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: prio <id> <A-Z|none>")
+	}
+
+	priority := strings.ToUpper(args[1])
+	if priority == "NONE" || priority == "-" {
+		priority = ""
+	} else if len(priority) != 1 || priority[0] < 'A' || priority[0] > 'Z' {
+		return fmt.Errorf("invalid priority: %s (expected A-Z or none)", args[1])
+	}
+
+	tasks, err := loadAndResolve(taskFile, listOpts, args[:1])
+	if err != nil {
+		return err
+	}
+
+	return updateTasks(taskFile, tasks, func(t *internal.Task) {
+		t.SetPriority(priority)
+	})
+}
+
+// DateCommand sets or clears the deadline or scheduled date of a task: taskeru due <id> <date|none>
+func DateCommand(taskFile *internal.TaskFile, listOpts ListOptions, scheduled bool, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: due|sched <id> <date|none>")
+	}
+
+	date, err := parseDateArg(strings.Join(args[1:], " "), scheduled)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := loadAndResolve(taskFile, listOpts, args[:1])
+	if err != nil {
+		return err
+	}
+
+	return updateTasks(taskFile, tasks, func(t *internal.Task) {
+		if scheduled {
+			t.ScheduledDate = date
+		} else {
+			t.DueDate = date
+		}
+	})
+}
+
+// parseDateArg parses a deadline or scheduled date given to a command. "none" clears the date.
+func parseDateArg(dateStr string, scheduled bool) (*time.Time, error) {
+	if dateStr == "none" || dateStr == "" {
+		return nil, nil
+	}
+
+	parsed, _ := internal.ParseNaturalDate(dateStr)
+	if parsed == nil {
+		return nil, fmt.Errorf("invalid date: %s", dateStr)
+	}
+	if scheduled {
+		// For scheduled dates, set to start of day
+		startOfDay := time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, parsed.Location())
+		parsed = &startOfDay
+	}
+	return parsed, nil
+}
+
+// ProjectCommand adds or removes projects: taskeru project add|rm <id> <project>...
+func ProjectCommand(taskFile *internal.TaskFile, listOpts ListOptions, args []string) error {
+	if len(args) < 3 || (args[0] != "add" && args[0] != "rm") {
+		return fmt.Errorf("usage: project add|rm <id> <project>...")
+	}
+
+	var projects []string
+	for _, project := range args[2:] {
+		projects = append(projects, strings.TrimPrefix(project, "+"))
+	}
+
+	tasks, err := loadAndResolve(taskFile, listOpts, args[1:2])
+	if err != nil {
+		return err
+	}
+
+	return updateTasks(taskFile, tasks, func(t *internal.Task) {
+		for _, project := range projects {
+			if args[0] == "add" {
+				if !slices.Contains(t.Projects, project) {
+					t.Projects = append(t.Projects, project)
+				}
+			} else {
+				t.Projects = slices.DeleteFunc(t.Projects, func(p string) bool { return p == project })
+			}
+		}
+	})
+}
+
+// NoteCommand appends a paragraph to the note of a task: taskeru note <id> <text>
+func NoteCommand(taskFile *internal.TaskFile, listOpts ListOptions, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: note <id> <text>")
+	}
+
+	text := strings.Join(args[1:], " ")
+
+	tasks, err := loadAndResolve(taskFile, listOpts, args[:1])
+	if err != nil {
+		return err
+	}
+
+	return updateTasks(taskFile, tasks, func(t *internal.Task) {
+		if t.Note == "" {
+			t.Note = text
+		} else {
+			t.Note = strings.TrimRight(t.Note, "\n") + "\n\n" + text
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"taskeru/internal"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupModifyTasks(t *testing.T) (*internal.TaskFile, []internal.Task) {
+	taskFile := internal.NewTaskFileForTesting(t)
+
+	tasks := []internal.Task{
+		*internal.NewTask("Task A"),
+		*internal.NewTask("Task B"),
+		*internal.NewTask("Task C"),
+	}
+	tasks[0].Priority = "A"
+	tasks[1].Priority = "B"
+	tasks[1].Projects = []string{"work"}
+	tasks[2].Priority = "C"
+	tasks[2].Projects = []string{"work"}
+	require.NoError(t, taskFile.AddTasks(tasks))
+
+	return taskFile, tasks
+}
+
+func findTask(t *testing.T, taskFile *internal.TaskFile, id string) internal.Task {
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	for _, task := range tasks {
+		if task.ID == id {
+			return task
+		}
+	}
+	t.Fatalf("task %s not found", id)
+	return internal.Task{}
+}
+
+func TestResolveTaskRefs(t *testing.T) {
+	_, tasks := setupModifyTasks(t)
+
+	resolved, err := resolveTaskRefs(tasks, ListOptions{}, []string{"1", tasks[2].ID[:len(tasks[2].ID)-1]})
+	require.NoError(t, err)
+	require.Equal(t, "Task A", resolved[0].Title)
+	require.Equal(t, "Task C", resolved[1].Title)
+
+	// List index respects the project filter
+	resolved, err = resolveTaskRefs(tasks, ListOptions{ProjectFilter: "work"}, []string{"2"})
+	require.NoError(t, err)
+	require.Equal(t, "Task C", resolved[0].Title)
+
+	_, err = resolveTaskRefs(tasks, ListOptions{}, []string{"4"})
+	require.ErrorContains(t, err, "out of range")
+
+	_, err = resolveTaskRefs(tasks, ListOptions{}, []string{"0"})
+	require.ErrorContains(t, err, "ambiguous")
+
+	_, err = resolveTaskRefs(tasks, ListOptions{}, nil)
+	require.Error(t, err)
+}
+
+func TestResolveTaskRefsShowAll(t *testing.T) {
+	taskFile, tasks := setupModifyTasks(t)
+	old := internal.NewTask("Old done task")
+	old.Status = internal.StatusDONE
+	completedAt := time.Now().AddDate(0, 0, -7)
+	old.CompletedAt = &completedAt
+	require.NoError(t, taskFile.AddTask(old))
+
+	// `ls` hides the old completed task, `ls -a` lists it after the open ones
+	_, err := loadAndResolve(taskFile, ListOptions{}, []string{"4"})
+	require.ErrorContains(t, err, "out of range")
+
+	resolved, err := loadAndResolve(taskFile, ListOptions{ShowAll: true}, []string{"1", "4"})
+	require.NoError(t, err)
+	require.Equal(t, tasks[0].ID, resolved[0].ID)
+	require.Equal(t, old.ID, resolved[1].ID)
+}
+
+func TestStatusCommand(t *testing.T) {
+	taskFile, tasks := setupModifyTasks(t)
+
+	captureStdout(t, func() {
+		require.NoError(t, StatusCommand(taskFile, ListOptions{}, internal.StatusDONE, []string{"1", "3"}))
+	})
+	require.Equal(t, internal.StatusDONE, findTask(t, taskFile, tasks[0].ID).Status)
+	require.NotNil(t, findTask(t, taskFile, tasks[0].ID).CompletedAt)
+	require.Equal(t, internal.StatusTODO, findTask(t, taskFile, tasks[1].ID).Status)
+	require.Equal(t, internal.StatusDONE, findTask(t, taskFile, tasks[2].ID).Status)
+
+	captureStdout(t, func() {
+		require.NoError(t, StatusCommand(taskFile, ListOptions{ProjectFilter: "work"}, internal.StatusDOING, []string{"1"}))
+	})
+	require.Equal(t, internal.StatusDOING, findTask(t, taskFile, tasks[1].ID).Status)
+}
+
+func TestRemoveCommand(t *testing.T) {
+	taskFile, tasks := setupModifyTasks(t)
+
+	captureStdout(t, func() {
+		require.NoError(t, RemoveCommand(taskFile, ListOptions{}, []string{tasks[1].ID}))
+	})
+
+	remaining, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, remaining, 2)
+}
+
+func TestPriorityCommand(t *testing.T) {
+	taskFile, tasks := setupModifyTasks(t)
+
+	captureStdout(t, func() {
+		require.NoError(t, PriorityCommand(taskFile, ListOptions{}, []string{"3", "a"}))
+	})
+	require.Equal(t, "A", findTask(t, taskFile, tasks[2].ID).Priority)
+
+	captureStdout(t, func() {
+		require.NoError(t, PriorityCommand(taskFile, ListOptions{}, []string{tasks[2].ID, "none"}))
+	})
+	require.Equal(t, "", findTask(t, taskFile, tasks[2].ID).Priority)
+
+	require.Error(t, PriorityCommand(taskFile, ListOptions{}, []string{"1", "AA"}))
+	require.Error(t, PriorityCommand(taskFile, ListOptions{}, []string{"1"}))
+}
+
+func TestDateCommand(t *testing.T) {
+	taskFile, tasks := setupModifyTasks(t)
+
+	captureStdout(t, func() {
+		require.NoError(t, DateCommand(taskFile, ListOptions{}, false, []string{"1", "2030-01-15"}))
+		require.NoError(t, DateCommand(taskFile, ListOptions{}, true, []string{"1", "2030-01-10"}))
+	})
+	task := findTask(t, taskFile, tasks[0].ID)
+	require.Equal(t, "2030-01-15 23:59:59", task.DueDate.Format("2006-01-02 15:04:05"))
+	require.Equal(t, "2030-01-10 00:00:00", task.ScheduledDate.Format("2006-01-02 15:04:05"))
+
+	captureStdout(t, func() {
+		require.NoError(t, DateCommand(taskFile, ListOptions{}, false, []string{tasks[0].ID, "none"}))
+	})
+	require.Nil(t, findTask(t, taskFile, tasks[0].ID).DueDate)
+
+	require.Error(t, DateCommand(taskFile, ListOptions{}, false, []string{"1", "someday"}))
+}
+
+func TestProjectCommand(t *testing.T) {
+	taskFile, tasks := setupModifyTasks(t)
+
+	captureStdout(t, func() {
+		require.NoError(t, ProjectCommand(taskFile, ListOptions{}, []string{"add", "2", "+urgent", "work"}))
+	})
+	require.Equal(t, []string{"work", "urgent"}, findTask(t, taskFile, tasks[1].ID).Projects)
+
+	captureStdout(t, func() {
+		require.NoError(t, ProjectCommand(taskFile, ListOptions{}, []string{"rm", tasks[1].ID, "work"}))
+	})
+	require.Equal(t, []string{"urgent"}, findTask(t, taskFile, tasks[1].ID).Projects)
+
+	require.Error(t, ProjectCommand(taskFile, ListOptions{}, []string{"move", "1", "work"}))
+}
+
+func TestNoteCommand(t *testing.T) {
+	taskFile, tasks := setupModifyTasks(t)
+
+	captureStdout(t, func() {
+		require.NoError(t, NoteCommand(taskFile, ListOptions{}, []string{"1", "first", "line"}))
+		require.NoError(t, NoteCommand(taskFile, ListOptions{}, []string{tasks[0].ID, "second"}))
+	})
+	require.Equal(t, "first line\n\nsecond", findTask(t, taskFile, tasks[0].ID).Note)
+}
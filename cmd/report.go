@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"taskeru/internal"
+)
+
+// ReportCommand prints reports:
+//
+//	taskeru report time [--since <date>] [--until <date>] [--by project|task|day]
+//	taskeru report standup|weekly [--since <date>] [--format text|md]
+func ReportCommand(taskFile *internal.TaskFile, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: report time|standup|weekly [options]")
+	}
+
+	switch args[0] {
+	case "time":
+		return timeReportCommand(taskFile, args[1:])
+	case "standup", "weekly":
+		return reviewReportCommand(taskFile, args[0], args[1:])
+	default:
+		return fmt.Errorf("unknown report: %s (expected time, standup or weekly)", args[0])
+	}
+}
+
+// parseReportOptions parses --name value and --name=value options of a report
+func parseReportOptions(args []string, defaults map[string]string) (map[string]string, error) {
+	opts := make(map[string]string, len(defaults))
+	for name, value := range defaults {
+		opts[name] = value
+	}
+
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(args[i], "--"), "=")
+		if _, ok := defaults[name]; !ok || !strings.HasPrefix(args[i], "--") {
+			return nil, fmt.Errorf("unknown report option: %s", args[i])
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("missing value for --%s", name)
+			}
+			i++
+			value = args[i]
+		}
+		opts[name] = value
+	}
+	return opts, nil
+}
+
+// parseReportDate parses the start or end of a report period as the start of a day.
+// Weekday names refer to the past, so "monday" is the start of this week.
+// Relative days like 7d count back from today.
+func parseReportDate(dateStr string) (time.Time, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if strings.HasSuffix(dateStr, "d") || strings.HasSuffix(dateStr, "w") {
+		if age, err := internal.ParseAge(dateStr); err == nil {
+			return today.Add(-age), nil
+		}
+	}
+
+	parsed, _ := internal.ParseNaturalDate(dateStr)
+	if parsed == nil {
+		return time.Time{}, fmt.Errorf("invalid date: %s", dateStr)
+	}
+	day := time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, now.Location())
+	if day.After(today) && isWeekdayName(dateStr) {
+		day = day.AddDate(0, 0, -7)
+	}
+	return day, nil
+}
+
+func isWeekdayName(s string) bool {
+	s = strings.ToLower(s)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return true
+		}
+	}
+	return false
+}
+
+func timeReportCommand(taskFile *internal.TaskFile, args []string) error {
+	opts, err := parseReportOptions(args, map[string]string{"since": "monday", "until": "", "by": "project"})
+	if err != nil {
+		return err
+	}
+
+	since, err := parseReportDate(opts["since"])
+	if err != nil {
+		return err
+	}
+	until := time.Now()
+	if opts["until"] != "" {
+		if until, err = parseReportDate(opts["until"]); err != nil {
+			return err
+		}
+		// Include the whole last day
+		until = until.AddDate(0, 0, 1)
+	}
+
+	// Past weeks may already be archived
+	tasks, err := taskFile.LoadTasksWithArchive()
+	if err != nil {
+		return fmt.Errorf("failed to load tasks: %w", err)
+	}
+
+	summaries, err := internal.SummarizeTrackedTime(tasks, since, until, opts["by"])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Time report %s - %s (by %s)\n", since.Format("2006-01-02"), until.Add(-time.Second).Format("2006-01-02"), opts["by"])
+	fmt.Println("------")
+	if len(summaries) == 0 {
+		fmt.Println("No time tracked.")
+		return nil
+	}
+
+	width := 0
+	for _, summary := range summaries {
+		width = max(width, len([]rune(summary.Key)))
+	}
+	var total time.Duration
+	for _, task := range tasks {
+		total += task.TrackedTime(since, until)
+	}
+	for _, summary := range summaries {
+		fmt.Printf("%-*s  %8s\n", width, summary.Key, internal.FormatDuration(summary.Duration))
+	}
+	fmt.Println("------")
+	fmt.Printf("%-*s  %8s\n", width, "Total", internal.FormatDuration(total))
+	return nil
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"taskeru/internal"
+)
+
+// reviewItem is a task in a section of a standup or weekly report
+type reviewItem struct {
+	Task   internal.Task
+	Detail string // e.g. "Wed 03-19" for done tasks, "due Fri 03-21" for upcoming ones
+}
+
+type reviewSection struct {
+	Name  string
+	Items []reviewItem
+}
+
+// reviewReport is what was completed since a day, what is in progress and what is due next
+type reviewReport struct {
+	Title    string
+	Since    time.Time
+	DueUntil time.Time
+	Sections []reviewSection
+}
+
+// buildReviewReport builds a "standup" or "weekly" report.
+// A standup covers the previous workday and the tasks due by tomorrow.
+// A weekly report covers the week since Monday and the tasks due in the next 7 days.
+// A non-zero since overrides the start of the period. Overdue tasks are always due next.
+func buildReviewReport(tasks []internal.Task, kind string, since time.Time, now time.Time) (reviewReport, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var report reviewReport
+	switch kind {
+	case "standup":
+		report.DueUntil = today.AddDate(0, 0, 2)
+		if since.IsZero() {
+			since = previousWorkday(today)
+		}
+		report.Title = fmt.Sprintf("Standup %s (since %s)", today.Format("2006-01-02"), since.Format("Mon 01-02"))
+	case "weekly":
+		report.DueUntil = today.AddDate(0, 0, 8)
+		if since.IsZero() {
+			// Weeks start on Monday
+			since = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		}
+		report.Title = fmt.Sprintf("Weekly review %s - %s", since.Format("2006-01-02"), today.Format("2006-01-02"))
+	default:
+		return report, fmt.Errorf("unknown report: %s (expected standup or weekly)", kind)
+	}
+	report.Since = since
+
+	var done, doing, waiting, upcoming []internal.Task
+	for _, task := range tasks {
+		switch {
+		case task.Status == internal.StatusDONE:
+			if task.CompletedAt != nil && !task.CompletedAt.Before(since) && task.CompletedAt.Before(now) {
+				done = append(done, task)
+			}
+			continue
+		case task.Status == internal.StatusDOING:
+			doing = append(doing, task)
+		case task.Status == internal.StatusWAITING:
+			waiting = append(waiting, task)
+		}
+		if !task.IsCompleted() && task.DueDate != nil && task.DueDate.Before(report.DueUntil) {
+			upcoming = append(upcoming, task)
+		}
+	}
+
+	sort.SliceStable(done, func(i, j int) bool { return done[i].CompletedAt.Before(*done[j].CompletedAt) })
+	internal.SortTasks(doing)
+	internal.SortTasks(waiting)
+	sort.SliceStable(upcoming, func(i, j int) bool { return upcoming[i].DueDate.Before(*upcoming[j].DueDate) })
+
+	report.Sections = []reviewSection{
+		{Name: "Done", Items: reviewItems(done, func(task internal.Task) string {
+			return task.CompletedAt.Format("Mon 01-02")
+		})},
+		{Name: "Doing", Items: reviewItems(doing, nil)},
+		{Name: "Waiting", Items: reviewItems(waiting, func(task internal.Task) string {
+			if len(task.BlockedBy) > 0 {
+				return fmt.Sprintf("blocked by %d", len(task.BlockedBy))
+			}
+			return ""
+		})},
+		{Name: "Due next", Items: reviewItems(upcoming, func(task internal.Task) string {
+			detail := "due " + task.DueDate.Format("Mon 01-02")
+			if task.DueDate.Before(today) {
+				detail += ", overdue"
+			}
+			return detail
+		})},
+	}
+	return report, nil
+}
+
+func reviewItems(tasks []internal.Task, detail func(internal.Task) string) []reviewItem {
+	items := make([]reviewItem, 0, len(tasks))
+	for _, task := range tasks {
+		item := reviewItem{Task: task}
+		if detail != nil {
+			item.Detail = detail(task)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// previousWorkday returns the day before, or Friday on Mondays and weekends
+func previousWorkday(today time.Time) time.Time {
+	day := today.AddDate(0, 0, -1)
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// writeReviewReport writes the report as plain text, or as Markdown for pasting into chat
+func writeReviewReport(out io.Writer, report reviewReport, markdown bool) error {
+	var b strings.Builder
+	if markdown {
+		fmt.Fprintf(&b, "## %s\n", report.Title)
+	} else {
+		fmt.Fprintf(&b, "%s\n", report.Title)
+	}
+
+	for _, section := range report.Sections {
+		if markdown {
+			fmt.Fprintf(&b, "\n### %s (%d)\n\n", section.Name, len(section.Items))
+		} else {
+			fmt.Fprintf(&b, "\n%s (%d)\n", section.Name, len(section.Items))
+		}
+		if len(section.Items) == 0 {
+			if markdown {
+				b.WriteString("- (none)\n")
+			} else {
+				b.WriteString("  (none)\n")
+			}
+			continue
+		}
+
+		for _, item := range section.Items {
+			if markdown {
+				b.WriteString("- " + item.Task.Title)
+			} else {
+				b.WriteString("  - " + item.Task.Title)
+			}
+			for _, project := range item.Task.Projects {
+				if markdown {
+					fmt.Fprintf(&b, " `+%s`", project)
+				} else {
+					b.WriteString(" +" + project)
+				}
+			}
+			if item.Detail != "" {
+				fmt.Fprintf(&b, " (%s)", item.Detail)
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// reviewReportCommand prints a report: taskeru report standup|weekly [--since <date>] [--format text|md]
+func reviewReportCommand(taskFile *internal.TaskFile, kind string, args []string) error {
+	opts, err := parseReportOptions(args, map[string]string{"since": "", "format": "text"})
+	if err != nil {
+		return err
+	}
+	if opts["format"] != "text" && opts["format"] != "md" && opts["format"] != "markdown" {
+		return fmt.Errorf("unknown format: %s (expected text or md)", opts["format"])
+	}
+
+	var since time.Time
+	if opts["since"] != "" {
+		if since, err = parseReportDate(opts["since"]); err != nil {
+			return err
+		}
+	}
+
+	// Tasks completed this week may already be archived
+	tasks, err := taskFile.LoadTasksWithArchive()
+	if err != nil {
+		return fmt.Errorf("failed to load tasks: %w", err)
+	}
+
+	report, err := buildReviewReport(tasks, kind, since, time.Now())
+	if err != nil {
+		return err
+	}
+	return writeReviewReport(os.Stdout, report, opts["format"] != "text")
+}
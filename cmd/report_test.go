@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"taskeru/internal"
+)
+
+func TestStartAndStopCommands(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	task := internal.NewTask("Timed task")
+	require.NoError(t, taskFile.AddTask(task))
+
+	captureStdout(t, func() {
+		require.NoError(t, StatusCommand(taskFile, ListOptions{}, internal.StatusDOING, []string{"1"}))
+	})
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.NotNil(t, tasks[0].RunningTimer())
+
+	captureStdout(t, func() {
+		require.NoError(t, StopCommand(taskFile, ListOptions{}, []string{"1"}))
+	})
+	tasks, err = taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Equal(t, internal.StatusTODO, tasks[0].Status)
+	require.Nil(t, tasks[0].RunningTimer())
+	require.Len(t, tasks[0].TimeLog, 1)
+
+	require.Error(t, StopCommand(taskFile, ListOptions{}, []string{"1"}), "timer is not running")
+}
+
+func TestTimeReportCommand(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+
+	start := time.Now().Add(-3 * time.Hour)
+	end := start.Add(90 * time.Minute)
+	work := internal.NewTask("Write report")
+	work.Projects = []string{"work"}
+	work.TimeLog = []internal.TimeEntry{{Start: start, End: &end}}
+	require.NoError(t, taskFile.AddTasks([]internal.Task{*work, *internal.NewTask("Untracked")}))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, ReportCommand(taskFile, []string{"time", "--since", "7d", "--by", "project"}))
+	})
+	require.Contains(t, output, "(by project)")
+	require.Regexp(t, `\+work\s+1h30m`, output)
+	require.Regexp(t, `Total\s+1h30m`, output)
+
+	output = captureStdout(t, func() {
+		require.NoError(t, ReportCommand(taskFile, []string{"time", "--since=7d", "--by=task"}))
+	})
+	require.Contains(t, output, "Write report")
+
+	require.Error(t, ReportCommand(taskFile, []string{"time", "--by", "week"}))
+	require.Error(t, ReportCommand(taskFile, []string{"time", "--since", "someday"}))
+	require.Error(t, ReportCommand(taskFile, []string{"time", "--verbose"}))
+}
+
+func TestParseReportDate(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	monday, err := parseReportDate("monday")
+	require.NoError(t, err)
+	require.Equal(t, time.Monday, monday.Weekday())
+	require.False(t, monday.After(today), "weekdays refer to the past")
+	require.True(t, today.Sub(monday) < 7*24*time.Hour)
+
+	weekAgo, err := parseReportDate("7d")
+	require.NoError(t, err)
+	require.Equal(t, today.AddDate(0, 0, -7), weekAgo)
+}
+
+func TestDailyReportShowsTrackedTime(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+
+	start := time.Date(2024, 5, 7, 9, 0, 0, 0, time.Local)
+	end := start.Add(2*time.Hour + 15*time.Minute)
+	task := internal.NewTask("Tracked only")
+	task.Created = start.AddDate(0, -1, 0)
+	task.Updated = start.AddDate(0, -1, 0)
+	task.TimeLog = []internal.TimeEntry{{Start: start, End: &end}}
+	require.NoError(t, taskFile.AddTask(task))
+
+	r := chi.NewRouter()
+	NewController(taskFile).registerRoutes(r)
+	req := httptest.NewRequest(http.MethodGet, "/daily/2024/5", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "⏱ 2h15m")
+	require.Contains(t, rec.Body.String(), "Tracked only")
+}
+
+func TestBuildReviewReport(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 3, 19, 10, 0, 0, 0, time.Local)
+	at := func(day, hour int) *time.Time {
+		t := time.Date(2025, 3, day, hour, 0, 0, 0, time.Local)
+		return &t
+	}
+	tasks := []internal.Task{
+		{ID: "1", Title: "Done yesterday", Status: internal.StatusDONE, CompletedAt: at(18, 15)},
+		{ID: "2", Title: "Done on Monday", Status: internal.StatusDONE, CompletedAt: at(17, 9)},
+		{ID: "3", Title: "Done last week", Status: internal.StatusDONE, CompletedAt: at(14, 9)},
+		{ID: "4", Title: "In progress", Status: internal.StatusDOING, Projects: []string{"work"}},
+		{ID: "5", Title: "Waiting for review", Status: internal.StatusWAITING, BlockedBy: []string{"4"}},
+		{ID: "6", Title: "Due tomorrow", Status: internal.StatusTODO, DueDate: at(20, 23)},
+		{ID: "7", Title: "Due next week", Status: internal.StatusTODO, DueDate: at(25, 23)},
+		{ID: "8", Title: "Overdue", Status: internal.StatusTODO, DueDate: at(10, 23)},
+		{ID: "9", Title: "Given up", Status: internal.StatusWONTDO, DueDate: at(20, 23)},
+	}
+	titles := func(section reviewSection) []string {
+		var titles []string
+		for _, item := range section.Items {
+			titles = append(titles, item.Task.Title)
+		}
+		return titles
+	}
+
+	standup, err := buildReviewReport(tasks, "standup", time.Time{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "Standup 2025-03-19 (since Tue 03-18)", standup.Title)
+	require.Equal(t, []string{"Done yesterday"}, titles(standup.Sections[0]))
+	require.Equal(t, []string{"In progress"}, titles(standup.Sections[1]))
+	require.Equal(t, []string{"Waiting for review"}, titles(standup.Sections[2]))
+	require.Equal(t, []string{"Overdue", "Due tomorrow"}, titles(standup.Sections[3]))
+	require.Equal(t, "due Mon 03-10, overdue", standup.Sections[3].Items[0].Detail)
+
+	weekly, err := buildReviewReport(tasks, "weekly", time.Time{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "Weekly review 2025-03-17 - 2025-03-19", weekly.Title)
+	require.Equal(t, []string{"Done on Monday", "Done yesterday"}, titles(weekly.Sections[0]))
+	require.Equal(t, []string{"Overdue", "Due tomorrow", "Due next week"}, titles(weekly.Sections[3]))
+
+	// On Mondays the standup covers Friday
+	monday := time.Date(2025, 3, 17, 10, 0, 0, 0, time.Local)
+	standup, err = buildReviewReport(tasks, "standup", time.Time{}, monday)
+	require.NoError(t, err)
+	require.Equal(t, "Standup 2025-03-17 (since Fri 03-14)", standup.Title)
+	require.Equal(t, []string{"Done last week", "Done on Monday"}, titles(standup.Sections[0]))
+
+	_, err = buildReviewReport(tasks, "monthly", time.Time{}, now)
+	require.Error(t, err)
+}
+
+func TestStandupReportCommand(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	done := internal.ParseTask("Write report +work")
+	done.SetStatus(internal.StatusDONE)
+	doing := internal.ParseTask("Review PR")
+	doing.SetStatus(internal.StatusDOING)
+	require.NoError(t, taskFile.AddTasks([]internal.Task{*done, *doing, *internal.ParseTask("Pay rent due:today")}))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, ReportCommand(taskFile, []string{"standup"}))
+	})
+	require.Contains(t, output, "Done (1)\n  - Write report +work (")
+	require.Contains(t, output, "Doing (1)\n  - Review PR\n")
+	require.Contains(t, output, "Waiting (0)\n  (none)\n")
+	require.Contains(t, output, "Due next (1)\n  - Pay rent (due ")
+
+	output = captureStdout(t, func() {
+		require.NoError(t, ReportCommand(taskFile, []string{"weekly", "--format", "md", "--since", "7d"}))
+	})
+	require.Contains(t, output, "## Weekly review ")
+	require.Contains(t, output, "### Done (1)\n\n- Write report `+work` (")
+
+	require.Error(t, ReportCommand(taskFile, []string{"standup", "--format", "html"}))
+}
+
+func TestWeeklyReportPage(t *testing.T) {
+	taskFile, handler := newAPITestServer(t)
+	require.NoError(t, taskFile.AddTask(internal.ParseTask("Write report +work due:tomorrow")))
+
+	rec := doAPIRequest(t, handler, http.MethodGet, "/report/weekly", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "Weekly review")
+	require.Contains(t, rec.Body.String(), "Write report")
+	// The Markdown for the copy button
+	require.Contains(t, rec.Body.String(), "### Due next (1)\n\n- Write report `&#43;work` (due ")
+	require.Contains(t, rec.Body.String(), `href="/report/weekly" class="active"`)
+
+	rec = doAPIRequest(t, handler, http.MethodGet, "/report/weekly?since=someday", "", nil)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 
 	"taskeru/internal"
 )
@@ -13,9 +14,13 @@ func Execute() {
 	// Parse global flags first
 	var taskFileName string
 	var projectFilter string
+	var viewName string
+	var showAll bool
 	var logFile string
 	flag.StringVar(&taskFileName, "t", "", "Path to task file")
 	flag.StringVar(&projectFilter, "p", "", "Filter tasks by project (for ls command)")
+	flag.StringVar(&viewName, "v", "", "Use a saved view from the config file")
+	flag.BoolVar(&showAll, "a", false, "Include old completed and archived tasks (for ls and list indexes)")
 	flag.StringVar(&logFile, "l", "log", "Path to log file")
 
 	// Custom usage to handle our command structure
@@ -36,6 +41,7 @@ func Execute() {
 
 	// Get command and remaining args
 	args := flag.Args()
+	taskFile.Actor = journalActor(args)
 
 	// logFile に書いていく
 	if logFile != "" {
@@ -52,9 +58,14 @@ func Execute() {
 		slog.SetDefault(slog.New(slog.DiscardHandler))
 	}
 
+	// The merge driver works on the files git gives it, not on the task file
+	if len(args) == 0 || (args[0] != "archive" && args[0] != "help" && args[0] != "init-config" && args[0] != "merge-driver") {
+		autoArchive(taskFile)
+	}
+
 	if len(args) == 0 {
 		// No command, run interactive mode (with project filter if specified)
-		if err := InteractiveCommandWithFilter(projectFilter, taskFile); err != nil {
+		if err := InteractiveCommandWithFilter(projectFilter, viewName, taskFile); err != nil {
 			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -64,15 +75,62 @@ func Execute() {
 	command := args[0]
 	nonFlagArgs := args[1:]
 
+	// Options deciding which tasks `ls` shows, and so what list indexes refer to
+	listOpts := ListOptions{ProjectFilter: projectFilter, View: strings.TrimPrefix(viewName, "@"), ShowAll: showAll}
+
 	var err error
 
 	switch command {
 	case "add", "a":
 		err = AddCommand(taskFile, nonFlagArgs)
 	case "ls", "list", "l":
-		err = ListCommand(taskFile, projectFilter)
+		var opts ListOptions
+		opts, err = parseListArgs(listOpts, nonFlagArgs)
+		if err == nil {
+			err = ListCommandWithOptions(taskFile, opts)
+		}
 	case "edit", "e":
 		err = EditCommand(taskFile)
+	case "done":
+		err = StatusCommand(taskFile, listOpts, internal.StatusDONE, nonFlagArgs)
+	case "start":
+		err = StatusCommand(taskFile, listOpts, internal.StatusDOING, nonFlagArgs)
+	case "wait":
+		err = StatusCommand(taskFile, listOpts, internal.StatusWAITING, nonFlagArgs)
+	case "wontdo":
+		err = StatusCommand(taskFile, listOpts, internal.StatusWONTDO, nonFlagArgs)
+	case "todo":
+		err = StatusCommand(taskFile, listOpts, internal.StatusTODO, nonFlagArgs)
+	case "stop":
+		err = StopCommand(taskFile, listOpts, nonFlagArgs)
+	case "report":
+		err = ReportCommand(taskFile, nonFlagArgs)
+	case "rm":
+		err = RemoveCommand(taskFile, listOpts, nonFlagArgs)
+	case "prio":
+		err = PriorityCommand(taskFile, listOpts, nonFlagArgs)
+	case "due":
+		err = DateCommand(taskFile, listOpts, false, nonFlagArgs)
+	case "sched":
+		err = DateCommand(taskFile, listOpts, true, nonFlagArgs)
+	case "project":
+		err = ProjectCommand(taskFile, listOpts, nonFlagArgs)
+	case "note":
+		err = NoteCommand(taskFile, listOpts, nonFlagArgs)
+	case "import":
+		err = ImportCommand(taskFile, nonFlagArgs)
+	case "export":
+		err = ExportCommand(taskFile, listOpts, nonFlagArgs)
+	case "archive":
+		err = ArchiveCommand(taskFile, nonFlagArgs)
+	case "sync":
+		err = SyncCommand(taskFile, nonFlagArgs)
+	case "merge-driver":
+		err = MergeDriverCommand(nonFlagArgs)
+	case "trash":
+		err = TrashCommand(taskFile, nonFlagArgs)
+	case "log":
+		err = LogCommand(taskFile, listOpts, nonFlagArgs)
 	case "httpd":
 		addr := ""
 		if len(nonFlagArgs) > 0 {
@@ -104,12 +162,50 @@ Usage:
 Options:
   -t <file>      Path to task file (default: ~/todo.json)
   -p <project>   Filter tasks by project (for ls and interactive mode)
+  -v <view>      Use a saved view from config.toml (for ls and interactive mode)
+  -a             Include old completed and archived tasks, so that list indexes follow ls -a
 
 Commands:
-  add <title>    Add a new task (supports +project, due:date, scheduled:date)
-  ls, list       List all tasks (use -p to filter by project)
+  add <title>    Add a new task (supports +project, due:date, scheduled:date, repeat:rule,
+                 after:id, blocks:id, est:2h, pts:3)
+  ls, list [-a] [@view] [filter]
+                 List tasks (use -p to filter by project, see Filter expressions)
+                 -a, --all: include old completed and archived tasks
+                 --format text|json|jsonl|csv|tsv|template, --template <go template>
   edit, e        Edit a task interactively
-  httpd [addr]   Start HTTP server for web UI (default: 127.0.0.1:7676)
+  done <id>...   Mark tasks as DONE (also: start, wait, wontdo, todo)
+  start <id>...  Mark tasks as DOING and start their timers
+  stop <id>...   Stop the timers of DOING tasks (back to TODO)
+  report time [--since monday] [--until <date>] [--by project|task|day]
+                 Summarize the tracked time
+  report standup|weekly [--since <date>] [--format text|md]
+                 Done since the last workday/Monday, DOING, WAITING and due next
+  rm <id>...     Delete tasks (moved to trash)
+  prio <id> <p>  Set priority (A-Z, or none)
+  due <id> <d>   Set deadline (date, or none)
+  sched <id> <d> Set scheduled date (date, or none)
+  project add|rm <id> <project>...
+                 Add or remove projects
+  note <id> <text>
+                 Append text to the task note
+  import --from todotxt|taskwarrior|csv [--dry-run] <file>
+                 Import tasks (duplicates are skipped, - reads stdin)
+  export ics [@view] [filter]
+                 Print tasks with due/scheduled dates as iCalendar (-p for one project)
+  export --format md|html|csv [--group project|status] [--title t] [@view] [-a] [filter]
+                 Print tasks as a document, grouped by project or status
+  archive [--older-than 30d]
+                 Move old completed tasks to <name>.archive/YYYY-MM.jsonl
+  sync           Commit the task file, pull and push its git repository (merged per task)
+  sync --install-merge-driver
+                 Make git merge/pull merge the task file per task too
+  log <id>       Show the recorded changes of a task (also deleted and archived ones)
+  trash ls       List deleted tasks
+  trash restore <id>...
+                 Restore deleted tasks (ID or index of trash ls)
+  trash purge --older-than 90d|--all
+                 Permanently remove deleted tasks
+  httpd [addr]   Start HTTP server for web UI and REST API (default: 127.0.0.1:7676)
   init-config    Create default configuration file
   help           Show this help message
 
@@ -118,11 +214,17 @@ Interactive Mode Keys:
   space         Toggle task done/todo
   D             Set deadline for selected task
   S             Set scheduled date for selected task
-  /             Search tasks (title, projects, notes)
+  /             Search tasks (text or filter expression)
   a             Show all tasks (including old completed)
+  v             Select saved view
   c             Create new task
+  C             Create subtask of selected task
+  z             Collapse/expand subtasks
   e             Edit selected task
   d             Delete selected task
+  u/ctrl+r      Undo/redo the last change
+  T             Show deleted tasks (Enter to restore)
+  A             Show agenda of the next 14 days (shift+arrows move tasks)
   r             Reload tasks
   q             Quit
 
@@ -131,9 +233,20 @@ Examples:
   taskeru add "Buy milk +personal"  # Add task with project
   taskeru add "Report due:tomorrow" # Add task with deadline
   taskeru add "Review sched:monday due:friday +work"  # Task with scheduled and due date
+  taskeru add "Weekly report repeat:weekly due:friday +work"  # Recurring task
+  taskeru add "Release after:0198a1b2"  # Task waiting for another task (ID prefix)
+  taskeru add "Design review est:1h30m pts:3"  # Task with estimate and effort points
   taskeru ls                        # List all tasks
   taskeru -p work ls                # List only tasks with +work project
+  taskeru ls status:DOING prio:<=B   # Filter tasks
+  taskeru ls @today                 # Use the [views.today] section of config.toml
+  taskeru -v standup                # Interactive mode with a saved view
+  taskeru ls --format json | jq '.[] | .title'  # Machine-readable output
+  taskeru ls --template '{{.ID}} {{.Title}} {{join .Projects ","}}'
   taskeru edit                      # Select and edit a task
+  taskeru done 1 3                  # Mark 1st and 3rd tasks of ls as done
+  taskeru -p work prio 2 A          # Index 2 of "taskeru -p work ls"
+  taskeru due 0198a1b2 friday       # Address a task by ID prefix
   taskeru -t /tmp/test.json add "Test task"  # Use different file
 
 Date formats (for due: and scheduled:/sched:):
@@ -148,6 +261,44 @@ Note:
   - due:date sets deadline (end of day, 23:59:59)
   - scheduled:date or sched:date sets when task becomes active (start of day, 00:00:00)
 
+Task references (<id>):
+  3                 # List index as printed by ls (respects -p and -v)
+  0198a1b2          # Unique prefix of the task ID
+
+Recurrence rules (for repeat:):
+  daily, weekly, monthly, yearly
+  weekdays          # Monday to Friday
+  mon,wed,fri       # Specific weekdays
+  3d, 2w, 6m        # Every N days/weeks/months from the previous date
+  +3d, +2w          # N days/weeks after completion
+
+Note:
+  - When a repeating task is marked DONE, its next occurrence is created
+    with due/scheduled dates moved forward
+
+Task relations (for after: and blocks:):
+  after:<id>        # This task waits for <id> (comma separated list allowed)
+  blocks:<id>       # <id> waits for this task
+  - A task with unfinished blockers is set to WAITING automatically, and goes
+    back to TODO when the last blocker is DONE
+
+Filter expressions (for ls, the / search, and ?q= in the web UI):
+  +work, project:work        # Task belongs to the project
+  status:DOING,WAITING       # Status (also: open, closed)
+  prio:A, prio:<=B, prio:none
+  due:<7d, due:today         # Also sched:, created:, updated:, completed:
+  has:note                   # Also: due, sched, prio, project, repeat, parent, blockers
+  id:0198a1b2                # ID prefix
+  report, "free text"        # Text in title, note or projects
+  -has:note                  # A leading - negates a term; all terms must match
+
+Saved views (config.toml):
+  [views.today]
+  filter = "status:open due:<=today"   # Filter expression
+  sort = "due"                         # default, due, scheduled, created, updated, title
+  show_all = false                     # Include old completed tasks
+
 Environment Variables:
-  EDITOR          Editor to use for editing (default: vim)`)
+  EDITOR          Editor to use for editing (default: vim)
+  NO_COLOR        Disable colors in ls output (also disabled when not a terminal)`)
 }
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"taskeru/internal"
+)
+
+// SyncCommand shares the task file through its git repository: taskeru sync [--install-merge-driver]
+func SyncCommand(taskFile *internal.TaskFile, args []string) error {
+	if len(args) == 1 && args[0] == "--install-merge-driver" {
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the taskeru executable: %w", err)
+		}
+		if err := taskFile.InstallMergeDriver(executable); err != nil {
+			return err
+		}
+		fmt.Printf("git merge and git pull now merge %s per task\n", taskFile.Path)
+		return nil
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("usage: sync [--install-merge-driver]")
+	}
+
+	result, err := taskFile.Sync()
+	if result.Committed {
+		fmt.Printf("Committed %s\n", taskFile.Path)
+	}
+	switch result.Pulled {
+	case "fast-forward":
+		fmt.Printf("Pulled changes from %s\n", result.Upstream)
+	case "merge":
+		fmt.Printf("Merged changes from %s per task\n", result.Upstream)
+	}
+	if result.Pushed {
+		fmt.Printf("Pushed to %s\n", result.Upstream)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to sync: %w", err)
+	}
+	if !result.Committed && result.Pulled == "" && !result.Pushed {
+		fmt.Println("Already up to date")
+	}
+	return nil
+}
+
+// MergeDriverCommand is run by git as the merge driver of the task file: taskeru merge-driver %O %A %B
+func MergeDriverCommand(args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("usage: merge-driver <base> <ours> <theirs>")
+	}
+	return internal.MergeTaskFiles(args[0], args[1], args[2])
+}
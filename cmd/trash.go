@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"taskeru/internal"
+)
+
+// TrashCommand manages deleted tasks: taskeru trash ls|restore <id>...|purge --older-than 90d|--all
+func TrashCommand(taskFile *internal.TaskFile, args []string) error {
+	if len(args) == 0 {
+		args = []string{"ls"}
+	}
+
+	switch args[0] {
+	case "ls", "list":
+		return trashListCommand(taskFile)
+	case "restore":
+		return trashRestoreCommand(taskFile, args[1:])
+	case "purge":
+		return trashPurgeCommand(taskFile, args[1:])
+	default:
+		return fmt.Errorf("usage: trash ls|restore <id>...|purge --older-than <age>|--all")
+	}
+}
+
+func trashListCommand(taskFile *internal.TaskFile) error {
+	trash, err := taskFile.LoadTrash()
+	if err != nil {
+		return err
+	}
+
+	if len(trash) == 0 {
+		fmt.Println("Trash is empty.")
+		return nil
+	}
+
+	var w strings.Builder
+	fmt.Fprintln(&w, "Deleted tasks:")
+	fmt.Fprintln(&w, "------")
+	for i, task := range trash {
+		fmt.Fprintf(&w, "%d. %s  %-7s %s \x1b[90m(%s)\x1b[0m\n",
+			i+1, task.DeletionTime().Format("2006-01-02 15:04"), task.DisplayStatus(), task.Title, task.ID)
+	}
+
+	output := w.String()
+	if !isColorTerminal(os.Stdout) {
+		output = ansiEscapeRegex.ReplaceAllString(output, "")
+	}
+	fmt.Print(output)
+	return nil
+}
+
+// resolveTrashRefs resolves list indexes of `trash ls` and ID prefixes to task IDs
+func resolveTrashRefs(trash []internal.Task, refs []string) ([]string, error) {
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("task ID or list index is required")
+	}
+
+	var ids []string
+	for _, ref := range refs {
+		if index, err := strconv.Atoi(ref); err == nil && !strings.HasPrefix(ref, "0") {
+			if index < 1 || index > len(trash) {
+				return nil, fmt.Errorf("list index %d is out of range (1-%d)", index, len(trash))
+			}
+			ids = append(ids, trash[index-1].ID)
+			continue
+		}
+
+		id, err := internal.ResolveTaskID(uniqueTasks(trash), ref)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// uniqueTasks drops later copies of tasks with the same ID, as a task can be deleted several times
+func uniqueTasks(tasks []internal.Task) []internal.Task {
+	seen := make(map[string]bool)
+	var unique []internal.Task
+	for _, task := range tasks {
+		if !seen[task.ID] {
+			seen[task.ID] = true
+			unique = append(unique, task)
+		}
+	}
+	return unique
+}
+
+func trashRestoreCommand(taskFile *internal.TaskFile, args []string) error {
+	trash, err := taskFile.LoadTrash()
+	if err != nil {
+		return err
+	}
+	ids, err := resolveTrashRefs(trash, args)
+	if err != nil {
+		return err
+	}
+
+	restored, err := taskFile.RestoreFromTrash(ids)
+	if err != nil {
+		return fmt.Errorf("failed to restore tasks: %w", err)
+	}
+	for _, task := range restored {
+		fmt.Printf("Task restored: %s\n", task.Title)
+	}
+	return nil
+}
+
+func trashPurgeCommand(taskFile *internal.TaskFile, args []string) error {
+	var olderThan string
+	all := false
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--older-than" && i+1 < len(args):
+			olderThan = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--older-than="):
+			olderThan = strings.TrimPrefix(args[i], "--older-than=")
+		case args[i] == "--all":
+			all = true
+		default:
+			return fmt.Errorf("usage: trash purge --older-than <age>|--all")
+		}
+	}
+	// Purging can't be undone, so everything is purged only when asked for
+	if all == (olderThan != "") {
+		return fmt.Errorf("usage: trash purge --older-than <age>|--all")
+	}
+
+	var age time.Duration
+	if olderThan != "" {
+		var err error
+		if age, err = internal.ParseAge(olderThan); err != nil {
+			return err
+		}
+	}
+
+	count, err := taskFile.PurgeTrash(age)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Purged %d deleted task", count)
+	if count != 1 {
+		fmt.Print("s")
+	}
+	fmt.Println(".")
+	return nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"taskeru/internal"
+)
+
+func TestTrashCommand(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	keep := internal.NewTask("Keep me")
+	deleted := internal.NewTask("Deleted task")
+	require.NoError(t, taskFile.AddTasks([]internal.Task{*keep, *deleted}))
+	require.NoError(t, taskFile.DeleteTask(deleted.ID))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, TrashCommand(taskFile, []string{"ls"}))
+	})
+	require.Contains(t, output, "1. ")
+	require.Contains(t, output, "Deleted task")
+	require.Contains(t, output, deleted.ID)
+
+	output = captureStdout(t, func() {
+		require.NoError(t, TrashCommand(taskFile, []string{"restore", "1"}))
+	})
+	require.Contains(t, output, "Task restored: Deleted task")
+
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+
+	output = captureStdout(t, func() {
+		require.NoError(t, TrashCommand(taskFile, nil))
+	})
+	require.Contains(t, output, "Trash is empty.")
+
+	require.Error(t, TrashCommand(taskFile, []string{"restore", deleted.ID}))
+	require.Error(t, TrashCommand(taskFile, []string{"empty"}))
+}
+
+func TestTrashPurgeCommand(t *testing.T) {
+	taskFile := internal.NewTaskFileForTesting(t)
+	task := internal.NewTask("Recently deleted")
+	require.NoError(t, taskFile.AddTask(task))
+	require.NoError(t, taskFile.DeleteTask(task.ID))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, TrashCommand(taskFile, []string{"purge", "--older-than", "90d"}))
+	})
+	require.Contains(t, output, "Purged 0 deleted tasks.")
+
+	// Purging everything has to be asked for
+	require.Error(t, TrashCommand(taskFile, []string{"purge"}))
+	require.Error(t, TrashCommand(taskFile, []string{"purge", "--all", "--older-than", "90d"}))
+
+	output = captureStdout(t, func() {
+		require.NoError(t, TrashCommand(taskFile, []string{"purge", "--all"}))
+	})
+	require.Contains(t, output, "Purged 1 deleted task.")
+
+	require.Error(t, TrashCommand(taskFile, []string{"purge", "--older-than", "later"}))
+}
@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ArchiveDir returns the directory of the per-month archive files, next to the task file.
+// It is named after the task file, so that task files in the same directory have their own archives.
+func (tf *TaskFile) ArchiveDir() string {
+	dir := filepath.Dir(tf.Path)
+	base := filepath.Base(tf.Path)
+	ext := filepath.Ext(base)
+	return filepath.Join(dir, base[:len(base)-len(ext)]+".archive")
+}
+
+func (tf *TaskFile) archiveFilePath(month time.Time) string {
+	return filepath.Join(tf.ArchiveDir(), month.Format("2006-01")+".jsonl")
+}
+
+// ArchiveTasks moves tasks completed before the cutoff into <name>.archive/YYYY-MM.jsonl,
+// by the month they were completed. Tasks with subtasks which can't be archived yet are kept.
+// It returns the number of archived tasks.
+func (tf *TaskFile) ArchiveTasks(before time.Time) (int, error) {
+	lock, err := tf.lock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock task file: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	tasks, err := tf.LoadTasks()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load tasks: %w", err)
+	}
+
+	archivable := make(map[string]bool)
+	for _, task := range tasks {
+		if task.IsCompleted() && task.CompletedAt != nil && task.CompletedAt.Before(before) {
+			archivable[task.ID] = true
+		}
+	}
+	// A parent stays as long as one of its subtasks stays, so that subtasks never lose their parent
+	for changed := true; changed; {
+		changed = false
+		for _, task := range tasks {
+			if task.ParentID != "" && archivable[task.ParentID] && !archivable[task.ID] {
+				delete(archivable, task.ParentID)
+				changed = true
+			}
+		}
+	}
+	if len(archivable) == 0 {
+		return 0, nil
+	}
+
+	byMonth := make(map[string][]Task)
+	var remaining []Task
+	for _, task := range tasks {
+		if !archivable[task.ID] {
+			remaining = append(remaining, task)
+			continue
+		}
+		path := tf.archiveFilePath(*task.CompletedAt)
+		byMonth[path] = append(byMonth[path], task)
+	}
+
+	// Write the archives first, so that a failure never loses tasks
+	if err := os.MkdirAll(tf.ArchiveDir(), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	for path, archived := range byMonth {
+		existing, err := readTaskLines(path)
+		if err != nil {
+			return 0, err
+		}
+		existing = slices.DeleteFunc(existing, func(t Task) bool { return archivable[t.ID] })
+		if err := writeTaskLines(path, ".archive-*.tmp", append(existing, archived...)); err != nil {
+			return 0, fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+
+	if err := tf.saveTasksAs(tasks, remaining, JournalCreated, JournalArchived); err != nil {
+		return 0, fmt.Errorf("failed to save tasks: %w", err)
+	}
+	return len(archivable), nil
+}
+
+// LoadArchivedTasks returns the tasks of all archive files. Other files in the archive directory are ignored.
+func (tf *TaskFile) LoadArchivedTasks() ([]Task, error) {
+	paths, err := filepath.Glob(filepath.Join(tf.ArchiveDir(), "[0-9][0-9][0-9][0-9]-[0-9][0-9].jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var tasks []Task
+	for _, path := range paths {
+		archived, err := readTaskLines(path)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, archived...)
+	}
+	return tasks, nil
+}
+
+// LoadTasksWithArchive returns the tasks of the task file followed by the archived tasks.
+// If a task is in both, the one in the task file wins.
+func (tf *TaskFile) LoadTasksWithArchive() ([]Task, error) {
+	tasks, err := tf.LoadTasks()
+	if err != nil {
+		return nil, err
+	}
+	archived, err := tf.LoadArchivedTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		seen[task.ID] = true
+	}
+	for _, task := range archived {
+		if !seen[task.ID] {
+			seen[task.ID] = true
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// readTaskLines reads a JSONL file of tasks. A missing file has no tasks.
+func readTaskLines(path string) ([]Task, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	return decodeTaskLines(file, path)
+}
+
+// decodeTaskLines reads tasks, one JSON object per line. Lines which aren't tasks are logged and skipped.
+func decodeTaskLines(r io.Reader, path string) ([]Task, error) {
+	var tasks []Task
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		var task Task
+		if err := json.Unmarshal([]byte(line), &task); err != nil {
+			slog.Error("Failed to unmarshal task",
+				slog.String("path", path),
+				slog.String("line_content", line),
+				slog.Any("error", err))
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return tasks, nil
+}
+
+// AutoArchive archives tasks completed more than the configured number of days ago,
+// when automatic archiving is enabled in the config file. after_days = 0 disables it.
+func (tf *TaskFile) AutoArchive(config *Config) (int, error) {
+	if !config.Archive.Auto || config.Archive.AfterDays <= 0 {
+		return 0, nil
+	}
+	return tf.ArchiveTasks(time.Now().AddDate(0, 0, -config.Archive.AfterDays))
+}
@@ -0,0 +1,210 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func completedTask(title string, completedAt time.Time) Task {
+	task := NewTask(title)
+	task.Status = StatusDONE
+	task.CompletedAt = &completedAt
+	return *task
+}
+
+func TestArchiveTasks(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+
+	january := time.Date(2025, 1, 15, 10, 0, 0, 0, time.Local)
+	february := time.Date(2025, 2, 3, 10, 0, 0, 0, time.Local)
+	recent := time.Now().Add(-time.Hour)
+
+	open := *NewTask("Still open")
+	jan := completedTask("Done in January", january)
+	feb := completedTask("Done in February", february)
+	fresh := completedTask("Done recently", recent)
+
+	// A done parent stays while one of its subtasks is open
+	parent := completedTask("Done parent", january)
+	child := *NewTask("Open child")
+	child.ParentID = parent.ID
+
+	require.NoError(t, taskFile.AddTasks([]Task{open, jan, feb, fresh, parent, child}))
+
+	count, err := taskFile.ArchiveTasks(time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	var titles []string
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+	}
+	require.ElementsMatch(t, []string{"Still open", "Done recently", "Done parent", "Open child"}, titles)
+
+	for month, title := range map[string]string{"2025-01": "Done in January", "2025-02": "Done in February"} {
+		archived, err := readTaskLines(filepath.Join(taskFile.ArchiveDir(), month+".jsonl"))
+		require.NoError(t, err)
+		require.Len(t, archived, 1)
+		require.Equal(t, title, archived[0].Title)
+	}
+
+	// Archived tasks are still there when asked for
+	all, err := taskFile.LoadTasksWithArchive()
+	require.NoError(t, err)
+	require.Len(t, all, 6)
+
+	// Nothing left to archive
+	count, err = taskFile.ArchiveTasks(time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+}
+
+func TestArchiveTasksAppendsToExistingMonth(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	january := time.Date(2025, 1, 15, 10, 0, 0, 0, time.Local)
+
+	require.NoError(t, taskFile.AddTask(ptr(completedTask("First", january))))
+	_, err := taskFile.ArchiveTasks(time.Now())
+	require.NoError(t, err)
+
+	require.NoError(t, taskFile.AddTask(ptr(completedTask("Second", january.AddDate(0, 0, 1)))))
+	_, err = taskFile.ArchiveTasks(time.Now())
+	require.NoError(t, err)
+
+	archived, err := taskFile.LoadArchivedTasks()
+	require.NoError(t, err)
+	require.Len(t, archived, 2)
+
+	entries, err := os.ReadDir(taskFile.ArchiveDir())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestAutoArchive(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	require.NoError(t, taskFile.AddTask(ptr(completedTask("Old", time.Now().AddDate(0, 0, -40)))))
+
+	config := DefaultConfig()
+	count, err := taskFile.AutoArchive(config)
+	require.NoError(t, err)
+	require.Equal(t, 0, count, "automatic archiving is off by default")
+
+	config.Archive.Auto = true
+	count, err = taskFile.AutoArchive(config)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestInteractiveShowAllReadsArchive(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	require.NoError(t, taskFile.AddTasks([]Task{
+		*NewTask("Open task"),
+		completedTask("Archived task", time.Now().AddDate(0, 0, -40)),
+	}))
+	_, err := taskFile.ArchiveTasks(time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+	require.Len(t, model.tasks, 1)
+
+	pressKey(t, model, "a")
+	require.Len(t, model.tasks, 2)
+
+	pressKey(t, model, "a")
+	require.Len(t, model.tasks, 1)
+}
+
+func TestInteractiveArchivedTasksAreReadOnly(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	require.NoError(t, taskFile.AddTask(ptr(completedTask("Archived task", time.Now().AddDate(0, 0, -40)))))
+	_, err := taskFile.ArchiveTasks(time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+	pressKey(t, model, "a")
+	require.Len(t, model.tasks, 1)
+
+	for _, key := range []string{" ", "s", "d", "+"} {
+		pressKey(t, model, key)
+		require.NoError(t, model.err)
+		require.Equal(t, "Archived tasks can't be changed", model.message)
+		require.False(t, model.confirmDelete)
+	}
+
+	archived, err := taskFile.LoadArchivedTasks()
+	require.NoError(t, err)
+	require.Equal(t, StatusDONE, archived[0].Status)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestArchiveDirPerTaskFile(t *testing.T) {
+	dir := t.TempDir()
+	work := NewTaskFileWithPath(filepath.Join(dir, "work.json"))
+	home := NewTaskFileWithPath(filepath.Join(dir, "home.json"))
+	require.NotEqual(t, work.ArchiveDir(), home.ArchiveDir())
+	require.Equal(t, filepath.Join(dir, "work.archive"), work.ArchiveDir())
+
+	january := time.Date(2025, 1, 15, 10, 0, 0, 0, time.Local)
+	require.NoError(t, work.AddTask(ptr(completedTask("Work task", january))))
+	require.NoError(t, home.AddTask(ptr(completedTask("Home task", january))))
+	_, err := work.ArchiveTasks(time.Now())
+	require.NoError(t, err)
+	_, err = home.ArchiveTasks(time.Now())
+	require.NoError(t, err)
+
+	// Files in the archive directory which aren't monthly archives are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(work.ArchiveDir(), "notes.jsonl"), []byte("not a task\n"), 0644))
+
+	archived, err := work.LoadArchivedTasks()
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	require.Equal(t, "Work task", archived[0].Title)
+
+	archived, err = home.LoadArchivedTasks()
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	require.Equal(t, "Home task", archived[0].Title)
+}
+
+func TestAutoArchiveDisabledByZeroDays(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	require.NoError(t, taskFile.AddTask(ptr(completedTask("Just done", time.Now().Add(-time.Minute)))))
+
+	config := DefaultConfig()
+	config.Archive.Auto = true
+	config.Archive.AfterDays = 0
+	count, err := taskFile.AutoArchive(config)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+}
+
+func TestLoadConfigRejectsNegativeAfterDays(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("HOME", configHome)
+	path, err := UserConfigPath()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("[archive]\nauto = true\nafter_days = -1\n"), 0644))
+
+	config, err := LoadConfig()
+	require.ErrorContains(t, err, "after_days")
+
+	// Even if the caller goes on, nothing is archived
+	taskFile := NewTaskFileForTesting(t)
+	require.NoError(t, taskFile.AddTask(ptr(completedTask("Just done", time.Now().Add(-time.Minute)))))
+	count, err := taskFile.AutoArchive(config)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -10,7 +11,9 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Editor EditorConfig `toml:"editor"`
+	Editor  EditorConfig    `toml:"editor"`
+	Archive ArchiveConfig   `toml:"archive"`
+	Views   map[string]View `toml:"views"`
 }
 
 // EditorConfig contains editor-related settings
@@ -18,12 +21,22 @@ type EditorConfig struct {
 	AddTimestamp bool `toml:"add_timestamp"`
 }
 
+// ArchiveConfig contains settings for archiving completed tasks
+type ArchiveConfig struct {
+	Auto      bool `toml:"auto"`       // Archive old completed tasks whenever taskeru runs
+	AfterDays int  `toml:"after_days"` // Days after completion before a task is archived, 0 disables auto
+}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
 		Editor: EditorConfig{
 			AddTimestamp: false, // Default to false for opt-in behavior
 		},
+		Archive: ArchiveConfig{
+			Auto:      false,
+			AfterDays: 30,
+		},
 	}
 }
 
@@ -65,9 +78,21 @@ func LoadConfig() (*Config, error) {
 		return DefaultConfig(), nil
 	}
 
+	// The settings are returned too, so that callers can tell whether the invalid one matters to them
+	if err := config.validate(); err != nil {
+		return config, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
 	return config, nil
 }
 
+// validate checks the settings which can't be used as they are
+func (c *Config) validate() error {
+	if c.Archive.AfterDays < 0 {
+		return fmt.Errorf("archive.after_days must not be negative: %d", c.Archive.AfterDays)
+	}
+	return nil
+}
+
 // SaveDefaultConfig creates a default config file
 func SaveDefaultConfig() error {
 	configPath, err := UserConfigPath()
@@ -99,6 +124,25 @@ func SaveDefaultConfig() error {
 # Add timestamp when editing tasks
 # When enabled, adds "## YYYY-MM-DD(Day) HH:MM" to notes
 add_timestamp = false
+
+[archive]
+# Move tasks completed more than after_days ago to <name>.archive/YYYY-MM.jsonl
+# next to the task file whenever taskeru runs (same as "taskeru archive")
+# after_days = 0 disables automatic archiving
+auto = false
+after_days = 30
+
+# Saved views, used as "taskeru ls @<name>" or "taskeru -v <name>"
+# filter: filter expression (same as "taskeru ls <filter>")
+# sort: default, due, scheduled, created, updated or title
+# show_all: include old completed tasks
+#
+# [views.today]
+# filter = "status:open due:<=today"
+# sort = "due"
+#
+# [views.blocked]
+# filter = "status:WAITING"
 `
 
 	_, err = file.WriteString(content)
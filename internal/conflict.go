@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ConflictError is returned by UpdateTaskWithMerge when a field was changed differently
+// by the caller and by another process
+type ConflictError struct {
+	TaskID  string
+	Fields  []string // JSON names of the clashing fields, e.g. "note"
+	Current Task     // The task as saved by the other process
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("task has been modified by another process (conflicting fields: %s)", strings.Join(e.Fields, ", "))
+}
+
+// mergeFieldGroups names fields which change together and are merged as one.
+// SetStatus also sets the completion time and starts or stops the timer.
+var mergeFieldGroups = map[string]string{
+	"completed_at": "status",
+	"time_log":     "status",
+}
+
+// taskMergeField is a field, or a group of fields, of Task merged as one
+type taskMergeField struct {
+	name    string
+	indexes []int
+}
+
+// taskMergeFields lists the fields of Task which are merged, in declaration order.
+// The ID and timestamps aren't, nor fields which aren't saved.
+var taskMergeFields = func() []taskMergeField {
+	var fields []taskMergeField
+	taskType := reflect.TypeOf(Task{})
+	for i := 0; i < taskType.NumField(); i++ {
+		name := taskFieldName(taskType.Field(i))
+		if name == "" || name == "id" || name == "created" || name == "updated" {
+			continue
+		}
+		if group, ok := mergeFieldGroups[name]; ok {
+			name = group
+		}
+		if j := slices.IndexFunc(fields, func(f taskMergeField) bool { return f.name == name }); j >= 0 {
+			fields[j].indexes = append(fields[j].indexes, i)
+		} else {
+			fields = append(fields, taskMergeField{name: name, indexes: []int{i}})
+		}
+	}
+	return fields
+}()
+
+// mergeTaskFields applies the fields edited changed from base to current, the version saved meanwhile.
+// If current changed one of them to something else too, current is left alone and a *ConflictError
+// lists the clashing fields.
+func mergeTaskFields(current *Task, base, edited Task) error {
+	currentValue := reflect.ValueOf(current).Elem()
+	baseValue := reflect.ValueOf(base)
+	editedValue := reflect.ValueOf(edited)
+
+	var conflicts []string
+	for _, field := range taskMergeFields {
+		if field.equal(baseValue, editedValue) {
+			continue
+		}
+		if !field.equal(baseValue, currentValue) && !field.equal(editedValue, currentValue) {
+			conflicts = append(conflicts, field.name)
+		}
+	}
+	if len(conflicts) > 0 {
+		return &ConflictError{TaskID: current.ID, Fields: conflicts, Current: *current}
+	}
+
+	applyChangedFields(current, base, edited)
+	return nil
+}
+
+// applyChangedFields sets the fields edited changed from base on task, whatever task has
+func applyChangedFields(task *Task, base, edited Task) {
+	taskValue := reflect.ValueOf(task).Elem()
+	baseValue := reflect.ValueOf(base)
+	editedValue := reflect.ValueOf(edited)
+
+	for _, field := range taskMergeFields {
+		if field.equal(baseValue, editedValue) {
+			continue
+		}
+		for _, i := range field.indexes {
+			taskValue.Field(i).Set(editedValue.Field(i))
+		}
+	}
+}
+
+// equal compares the field as it is saved, so that e.g. a nil and an empty slice are the same
+func (f taskMergeField) equal(a, b reflect.Value) bool {
+	for _, i := range f.indexes {
+		x, err := fieldJSON(a.Field(i))
+		if err != nil {
+			return false
+		}
+		y, err := fieldJSON(b.Field(i))
+		if err != nil || !bytes.Equal(x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// taskFieldName returns the JSON name of a field of Task, or "" if the field isn't saved
+func taskFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// fieldJSON returns a field as it is saved. Zero values and empty slices, which are omitted, are nil.
+func fieldJSON(v reflect.Value) (json.RawMessage, error) {
+	if v.IsZero() || v.Kind() == reflect.Slice && v.Len() == 0 {
+		return nil, nil
+	}
+	return json.Marshal(v.Interface())
+}
+
+// cloneTasks copies tasks with cloneTask
+func cloneTasks(tasks []Task) []Task {
+	cloned := make([]Task, len(tasks))
+	for i, task := range tasks {
+		cloned[i] = cloneTask(task)
+	}
+	return cloned
+}
+
+// cloneTask copies a task, so that changing the copy's slices doesn't change the original
+func cloneTask(task Task) Task {
+	task.Projects = slices.Clone(task.Projects)
+	task.BlockedBy = slices.Clone(task.BlockedBy)
+	task.TimeLog = slices.Clone(task.TimeLog)
+	task.Blocks = slices.Clone(task.Blocks)
+	return task
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateTaskWithMerge(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	task := NewTask("Write report")
+	task.Projects = []string{"work"}
+	require.NoError(t, taskFile.AddTask(task))
+	original := loadTaskByID(t, taskFile, task.ID)
+
+	// Another process raises the priority
+	require.NoError(t, taskFile.UpdateTaskWithConflictCheck(task.ID, original.Updated, func(t *Task) {
+		t.Priority = "A"
+		t.Projects = append(t.Projects, "urgent")
+	}))
+
+	// while we edit the note of the version we loaded before
+	require.NoError(t, taskFile.UpdateTaskWithMerge(original, func(t *Task) {
+		t.Note = "First draft"
+		t.SetStatus(StatusDOING)
+	}))
+
+	merged := loadTaskByID(t, taskFile, task.ID)
+	require.Equal(t, "A", merged.Priority)
+	require.Equal(t, []string{"work", "urgent"}, merged.Projects)
+	require.Equal(t, "First draft", merged.Note)
+	require.Equal(t, StatusDOING, merged.Status)
+	require.Len(t, merged.TimeLog, 1)
+	require.True(t, merged.Updated.After(original.Updated))
+
+	// The strict check still fails
+	require.Error(t, taskFile.UpdateTaskWithConflictCheck(task.ID, original.Updated, func(t *Task) {}))
+}
+
+func TestUpdateTaskWithMergeConflict(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	task := NewTask("Write report")
+	require.NoError(t, taskFile.AddTask(task))
+	original := loadTaskByID(t, taskFile, task.ID)
+
+	require.NoError(t, taskFile.UpdateTaskWithConflictCheck(task.ID, original.Updated, func(t *Task) {
+		t.Note = "Theirs"
+		t.Priority = "B"
+		t.Title = "Write the report"
+	}))
+
+	err := taskFile.UpdateTaskWithMerge(original, func(t *Task) {
+		t.Title = "Write the report" // The same change isn't a conflict
+		t.Note = "Ours"
+		t.Priority = "C"
+		t.SetStatus(StatusDONE)
+	})
+	var conflict *ConflictError
+	require.True(t, errors.As(err, &conflict))
+	require.Equal(t, task.ID, conflict.TaskID)
+	require.Equal(t, []string{"priority", "note"}, conflict.Fields)
+	require.Equal(t, "Theirs", conflict.Current.Note)
+	require.Contains(t, err.Error(), "modified by another process")
+
+	// Nothing was saved
+	current := loadTaskByID(t, taskFile, task.ID)
+	require.Equal(t, "Theirs", current.Note)
+	require.Equal(t, StatusTODO, current.Status)
+
+	// Merging again from the current version resolves the conflict with our values
+	require.NoError(t, taskFile.UpdateTaskWithMerge(conflict.Current, func(t *Task) {
+		t.Note = "Ours"
+	}))
+	require.Equal(t, "Ours", loadTaskByID(t, taskFile, task.ID).Note)
+}
+
+func TestMergeTaskFieldsGroupsStatus(t *testing.T) {
+	base := Task{ID: "1", Status: StatusTODO}
+	edited := cloneTask(base)
+	edited.SetStatus(StatusDONE)
+	current := cloneTask(base)
+	current.SetStatus(StatusDOING)
+
+	var conflict *ConflictError
+	require.True(t, errors.As(mergeTaskFields(&current, base, edited), &conflict))
+	// The completion time and the timer change with the status
+	require.Equal(t, []string{"status"}, conflict.Fields)
+}
@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CountOpenBlockers returns the number of unfinished blockers for every blocked task.
+// Blockers which no longer exist are not counted.
+func CountOpenBlockers(tasks []Task) map[string]int {
+	open := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		open[task.ID] = !task.IsCompleted()
+	}
+
+	counts := make(map[string]int)
+	for _, task := range tasks {
+		for _, blockerID := range task.BlockedBy {
+			if open[blockerID] {
+				counts[task.ID]++
+			}
+		}
+	}
+	return counts
+}
+
+// hasOpenBlockers reports whether any blocker of tasks[i] is still open
+func hasOpenBlockers(tasks []Task, i int) bool {
+	for _, blockerID := range tasks[i].BlockedBy {
+		for _, task := range tasks {
+			if task.ID == blockerID && !task.IsCompleted() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// linkDependencies resolves the after:/blocks: references of tasks[i] to full task IDs.
+// Tasks which gain an open blocker are moved from TODO to WAITING.
+func linkDependencies(tasks []Task, i int) error {
+	blockedBy := make([]string, 0, len(tasks[i].BlockedBy))
+	for _, ref := range tasks[i].BlockedBy {
+		blockerID, err := ResolveTaskID(tasks, ref)
+		if err != nil {
+			return fmt.Errorf("invalid blocker: %w", err)
+		}
+		if blockerID == tasks[i].ID {
+			return fmt.Errorf("task cannot block itself")
+		}
+		if !slices.Contains(blockedBy, blockerID) {
+			blockedBy = append(blockedBy, blockerID)
+		}
+	}
+	if len(blockedBy) > 0 {
+		tasks[i].BlockedBy = blockedBy
+	} else {
+		tasks[i].BlockedBy = nil
+	}
+
+	for _, ref := range tasks[i].Blocks {
+		blockedID, err := ResolveTaskID(tasks, ref)
+		if err != nil {
+			return fmt.Errorf("invalid blocked task: %w", err)
+		}
+		if blockedID == tasks[i].ID {
+			return fmt.Errorf("task cannot block itself")
+		}
+		for j := range tasks {
+			if tasks[j].ID == blockedID && !slices.Contains(tasks[j].BlockedBy, tasks[i].ID) {
+				tasks[j].BlockedBy = append(tasks[j].BlockedBy, tasks[i].ID)
+				if tasks[j].Status == StatusTODO && !tasks[i].IsCompleted() {
+					tasks[j].SetStatus(StatusWAITING)
+				}
+			}
+		}
+	}
+	tasks[i].Blocks = nil
+
+	if tasks[i].Status == StatusTODO && hasOpenBlockers(tasks, i) {
+		tasks[i].SetStatus(StatusWAITING)
+	}
+	return nil
+}
+
+// releaseDependents moves tasks waiting for blockerID back to TODO once all their blockers are finished
+func releaseDependents(tasks []Task, blockerID string) {
+	for i := range tasks {
+		if tasks[i].Status != StatusWAITING || !slices.Contains(tasks[i].BlockedBy, blockerID) {
+			continue
+		}
+		if !hasOpenBlockers(tasks, i) {
+			tasks[i].SetStatus(StatusTODO)
+		}
+	}
+}
+
+// checkDependencyCycles returns an error if the blocked-by relations form a cycle through one of
+// the tasks with the given IDs. Cycles elsewhere, e.g. from an import, don't stop changes of other tasks.
+func checkDependencyCycles(tasks []Task, ids []string) error {
+	edges := make(map[string][]string, len(tasks))
+	titles := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		edges[task.ID] = task.BlockedBy
+		titles[task.ID] = task.Title
+	}
+
+	for _, start := range ids {
+		visited := make(map[string]bool)
+		// visit returns the task blocked by start, which closes a cycle, if id leads back to start
+		var visit func(id string) (string, bool)
+		visit = func(id string) (string, bool) {
+			for _, next := range edges[id] {
+				if next == start {
+					return id, true
+				}
+				if _, ok := edges[next]; !ok || visited[next] {
+					continue
+				}
+				visited[next] = true
+				if last, ok := visit(next); ok {
+					return last, true
+				}
+			}
+			return "", false
+		}
+		if last, ok := visit(start); ok {
+			return fmt.Errorf("dependency cycle detected between %q and %q", titles[last], titles[start])
+		}
+	}
+	return nil
+}
+
+// keepUnresolvedRefs puts the after:/blocks: references of tasks[i] which match no task, or more
+// than one, back into its title. They were ordinary words, like after:face, rather than task IDs.
+// Blockers in linked were resolved before and stay, even if their task is gone.
+func keepUnresolvedRefs(tasks []Task, i int, linked []string) {
+	typed := tasks[i].TypedTitle
+	tasks[i].TypedTitle = ""
+
+	resolved := func(ref string) bool {
+		if slices.Contains(linked, ref) {
+			return true
+		}
+		_, err := ResolveTaskID(tasks, ref)
+		return err == nil
+	}
+	if !slices.ContainsFunc(tasks[i].BlockedBy, func(ref string) bool { return !resolved(ref) }) &&
+		!slices.ContainsFunc(tasks[i].Blocks, func(ref string) bool { return !resolved(ref) }) {
+		return
+	}
+
+	// Parse the title again, so that the words stay where they were typed
+	if typed != "" {
+		reparsed := parseTask(typed, func(ref string) bool { return looksLikeTaskID(ref) && resolved(ref) })
+		tasks[i].Title = reparsed.Title
+		tasks[i].BlockedBy = append(slices.Clone(linked), reparsed.BlockedBy...)
+		tasks[i].Blocks = reparsed.Blocks
+		return
+	}
+
+	var words []string
+	unresolved := func(prefix string) func(string) bool {
+		return func(ref string) bool {
+			if resolved(ref) {
+				return false
+			}
+			words = append(words, prefix+ref)
+			return true
+		}
+	}
+	tasks[i].BlockedBy = slices.DeleteFunc(slices.Clone(tasks[i].BlockedBy), unresolved("after:"))
+	tasks[i].Blocks = slices.DeleteFunc(slices.Clone(tasks[i].Blocks), unresolved("blocks:"))
+	tasks[i].Title = strings.TrimSpace(tasks[i].Title + " " + strings.Join(words, " "))
+}
@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractDependenciesFromTitle(t *testing.T) {
+	title, blockedBy, blocks := ExtractDependenciesFromTitle("Deploy after:0198abc,0198def blocks:0198fff +work")
+	require.Equal(t, "Deploy +work", title)
+	require.Equal(t, []string{"0198abc", "0198def"}, blockedBy)
+	require.Equal(t, []string{"0198fff"}, blocks)
+
+	// Words which can't be task IDs stay in the title
+	title, blockedBy, blocks = ExtractDependenciesFromTitle("Meet after:lunch blocks:0198fff")
+	require.Equal(t, "Meet after:lunch", title)
+	require.Nil(t, blockedBy)
+	require.Equal(t, []string{"0198fff"}, blocks)
+
+	// Short prefixes and plain numbers are words too
+	title, blockedBy, _ = ExtractDependenciesFromTitle("Release after:0 after:1500 after:0198a")
+	require.Equal(t, "Release after:0 after:1500", title)
+	require.Equal(t, []string{"0198a"}, blockedBy)
+
+	title, blockedBy, blocks = ExtractDependenciesFromTitle("No relations")
+	require.Equal(t, "No relations", title)
+	require.Nil(t, blockedBy)
+	require.Nil(t, blocks)
+}
+
+func loadTaskByID(t *testing.T, taskFile *TaskFile, id string) Task {
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	for _, task := range tasks {
+		if task.ID == id {
+			return task
+		}
+	}
+	t.Fatalf("task %s not found", id)
+	return Task{}
+}
+
+func TestBlockedTaskWaitsForBlocker(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+
+	blocker1 := NewTask("Write code")
+	blocker2 := NewTask("Write docs")
+	require.NoError(t, taskFile.AddTasks([]Task{*blocker1, *blocker2}))
+
+	blocked := ParseTask("Release after:" + blocker1.ID + "," + blocker2.ID[:len(blocker2.ID)-2])
+	require.NoError(t, taskFile.AddTask(blocked))
+
+	got := loadTaskByID(t, taskFile, blocked.ID)
+	require.Equal(t, "Release", got.Title)
+	require.Equal(t, StatusWAITING, got.Status)
+	require.Equal(t, []string{blocker1.ID, blocker2.ID}, got.BlockedBy)
+
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Equal(t, 2, CountOpenBlockers(tasks)[blocked.ID])
+
+	// Finishing the first blocker keeps the task waiting
+	require.NoError(t, taskFile.UpdateTaskWithConflictCheck(blocker1.ID, blocker1.Updated, func(t *Task) {
+		t.SetStatus(StatusDONE)
+	}))
+	require.Equal(t, StatusWAITING, loadTaskByID(t, taskFile, blocked.ID).Status)
+
+	// Finishing the last blocker moves it to TODO
+	require.NoError(t, taskFile.UpdateTaskWithConflictCheck(blocker2.ID, blocker2.Updated, func(t *Task) {
+		t.SetStatus(StatusWONTDO)
+	}))
+	require.Equal(t, StatusTODO, loadTaskByID(t, taskFile, blocked.ID).Status)
+}
+
+func TestBlocksTokenLinksExistingTask(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+
+	later := NewTask("Later")
+	require.NoError(t, taskFile.AddTask(later))
+
+	first := ParseTask("First blocks:" + later.ID)
+	require.NoError(t, taskFile.AddTask(first))
+
+	got := loadTaskByID(t, taskFile, later.ID)
+	require.Equal(t, []string{first.ID}, got.BlockedBy)
+	require.Equal(t, StatusWAITING, got.Status)
+
+	// Deleting the blocker releases the waiting task
+	require.NoError(t, taskFile.DeleteTask(first.ID))
+	require.Equal(t, StatusTODO, loadTaskByID(t, taskFile, later.ID).Status)
+}
+
+func TestDependencyErrors(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+
+	a := NewTask("A")
+	b := NewTask("B")
+	b.BlockedBy = []string{a.ID}
+	require.NoError(t, taskFile.AddTasks([]Task{*a, *b}))
+
+	t.Run("unknown blocker stays in the title", func(t *testing.T) {
+		for _, title := range []string{"Meet after:lunch", "Feed after:cafe"} {
+			task := ParseTask(title)
+			require.NoError(t, taskFile.AddTask(task))
+			got := loadTaskByID(t, taskFile, task.ID)
+			require.Equal(t, title, got.Title)
+			require.Empty(t, got.BlockedBy)
+			require.Equal(t, StatusTODO, got.Status)
+		}
+	})
+
+	t.Run("ambiguous or unknown reference stays where it was typed", func(t *testing.T) {
+		task := ParseTask("Meet after:" + a.ID[:4] + " and after:beef later blocks:" + b.ID)
+		require.NoError(t, taskFile.AddTask(task))
+		require.Equal(t, "Meet after:"+a.ID[:4]+" and after:beef later", task.Title)
+		require.Empty(t, task.BlockedBy)
+		require.Contains(t, loadTaskByID(t, taskFile, b.ID).BlockedBy, task.ID)
+	})
+
+	t.Run("existing cycle doesn't block other tasks", func(t *testing.T) {
+		x := NewTask("X")
+		y := NewTask("Y")
+		x.BlockedBy = []string{y.ID}
+		y.BlockedBy = []string{x.ID}
+		tasks, err := taskFile.LoadTasks()
+		require.NoError(t, err)
+		// e.g. imported or merged by sync
+		require.NoError(t, taskFile.saveTasks(tasks, append(tasks, *x, *y)))
+
+		require.NoError(t, taskFile.AddTask(ParseTask("Unrelated after:"+a.ID)))
+		require.ErrorContains(t, taskFile.AddTask(ParseTask("Z after:"+x.ID+" blocks:"+y.ID)), "dependency cycle")
+	})
+
+	t.Run("cycle is rejected on update", func(t *testing.T) {
+		current := loadTaskByID(t, taskFile, a.ID)
+		err := taskFile.UpdateTaskWithConflictCheck(a.ID, current.Updated, func(t *Task) {
+			t.BlockedBy = append(t.BlockedBy, b.ID)
+		})
+		require.ErrorContains(t, err, "dependency cycle")
+		require.Empty(t, loadTaskByID(t, taskFile, a.ID).BlockedBy)
+	})
+
+	t.Run("self reference is rejected", func(t *testing.T) {
+		current := loadTaskByID(t, taskFile, a.ID)
+		err := taskFile.UpdateTaskWithConflictCheck(a.ID, current.Updated, func(t *Task) {
+			t.BlockedBy = []string{a.ID}
+		})
+		require.ErrorContains(t, err, "cannot block itself")
+	})
+}
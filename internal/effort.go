@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	estimateRegex = regexp.MustCompile(`\s+est:(\S+)`)
+	pointsRegex   = regexp.MustCompile(`\s+pts:(\S+)`)
+)
+
+// ExtractEffortFromTitle extracts the estimate (est:2h, est:30m, est:1h30m) and
+// effort points (pts:3) from title. Values which can't be parsed stay in the title.
+func ExtractEffortFromTitle(title string) (string, time.Duration, float64) {
+	var estimate time.Duration
+	if match := estimateRegex.FindStringSubmatch(title); match != nil {
+		if d, err := ParseEstimate(match[1]); err == nil {
+			estimate = d
+			title = estimateRegex.ReplaceAllString(title, "")
+		}
+	}
+
+	var points float64
+	if match := pointsRegex.FindStringSubmatch(title); match != nil {
+		if p, err := strconv.ParseFloat(match[1], 64); err == nil && p >= 0 {
+			points = p
+			title = pointsRegex.ReplaceAllString(title, "")
+		}
+	}
+
+	return strings.TrimSpace(title), estimate, points
+}
+
+// ParseEstimate parses an estimate like 2h, 30m, 1h30m or 1.5h, rounded to minutes
+func ParseEstimate(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid estimate: %s (expected e.g. 2h, 30m or 1h30m)", s)
+	}
+	return d.Round(time.Minute), nil
+}
+
+// Estimate returns the estimated effort of the task
+func (t *Task) Estimate() time.Duration {
+	return time.Duration(t.EstimateMinutes) * time.Minute
+}
+
+// SetEstimate sets the estimated effort of the task
+func (t *Task) SetEstimate(d time.Duration) {
+	t.EstimateMinutes = int(d.Round(time.Minute) / time.Minute)
+}
+
+// RemainingEstimate returns the estimate minus the time already tracked.
+// Completed tasks have nothing remaining.
+func (t *Task) RemainingEstimate() time.Duration {
+	if t.IsCompleted() {
+		return 0
+	}
+	return max(t.Estimate()-t.TotalTrackedTime(), 0)
+}
+
+// DisplayEffort returns the estimate and points as title tokens, e.g. "est:2h pts:3"
+func (t *Task) DisplayEffort() string {
+	var parts []string
+	if t.EstimateMinutes > 0 {
+		parts = append(parts, "est:"+FormatEstimate(t.Estimate()))
+	}
+	if t.Points > 0 {
+		parts = append(parts, "pts:"+FormatPoints(t.Points))
+	}
+	return strings.Join(parts, " ")
+}
+
+// FormatEstimate formats an estimate the way it is written in titles, like 2h, 45m or 1h30m
+func FormatEstimate(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	}
+}
+
+// FormatPoints formats effort points without needless decimals
+func FormatPoints(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// EffortTotals is the sum of remaining estimates and points of some tasks
+type EffortTotals struct {
+	Remaining time.Duration
+	Points    float64
+}
+
+// SumEffort totals the remaining estimates and the points of the tasks
+func SumEffort(tasks []Task) EffortTotals {
+	var totals EffortTotals
+	for _, task := range tasks {
+		totals.Remaining += task.RemainingEstimate()
+		totals.Points += task.Points
+	}
+	return totals
+}
+
+// String returns the totals like "est:5h pts:8", leaving out zero values
+func (e EffortTotals) String() string {
+	var parts []string
+	if e.Remaining > 0 {
+		parts = append(parts, "est:"+FormatEstimate(e.Remaining))
+	}
+	if e.Points > 0 {
+		parts = append(parts, "pts:"+FormatPoints(e.Points))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskWithEffort(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedTitle   string
+		expectedMinutes int
+		expectedPoints  float64
+	}{
+		{"Write report est:2h pts:3", "Write report", 120, 3},
+		{"Review est:1h30m +work", "Review", 90, 0},
+		{"Quick fix pts:0.5", "Quick fix", 0, 0.5},
+		{"Broken est:soon pts:many", "Broken est:soon pts:many", 0, 0},
+		{"Plain task", "Plain task", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			task := ParseTask(tt.input)
+			require.Equal(t, tt.expectedTitle, task.Title)
+			require.Equal(t, tt.expectedMinutes, task.EstimateMinutes)
+			require.Equal(t, tt.expectedPoints, task.Points)
+		})
+	}
+}
+
+func TestDisplayEffort(t *testing.T) {
+	task := Task{EstimateMinutes: 90, Points: 3}
+	require.Equal(t, "est:1h30m pts:3", task.DisplayEffort())
+
+	task = Task{EstimateMinutes: 45}
+	require.Equal(t, "est:45m", task.DisplayEffort())
+
+	require.Empty(t, (&Task{}).DisplayEffort())
+}
+
+func TestRemainingEstimate(t *testing.T) {
+	start := time.Now().Add(-3 * time.Hour)
+	end := start.Add(30 * time.Minute)
+	task := Task{Status: StatusDOING, EstimateMinutes: 120, TimeLog: []TimeEntry{{Start: start, End: &end}}}
+	require.Equal(t, 90*time.Minute, task.RemainingEstimate())
+
+	// More time tracked than estimated leaves nothing
+	task.EstimateMinutes = 20
+	require.Equal(t, time.Duration(0), task.RemainingEstimate())
+
+	task = Task{Status: StatusDONE, EstimateMinutes: 120}
+	require.Equal(t, time.Duration(0), task.RemainingEstimate())
+}
+
+func TestSumEffort(t *testing.T) {
+	tasks := []Task{
+		{Status: StatusTODO, EstimateMinutes: 120, Points: 3},
+		{Status: StatusTODO, EstimateMinutes: 30, Points: 2},
+		{Status: StatusTODO},
+	}
+	totals := SumEffort(tasks)
+	require.Equal(t, 150*time.Minute, totals.Remaining)
+	require.Equal(t, 5.0, totals.Points)
+	require.Equal(t, "est:2h30m pts:5", totals.String())
+
+	require.Empty(t, SumEffort(nil).String())
+}
@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// TaskChange is the change of a single task. Before is nil for an added task,
+// After is nil for a deleted task.
+type TaskChange struct {
+	Before *Task
+	After  *Task
+}
+
+func (c TaskChange) taskID() string {
+	if c.After != nil {
+		return c.After.ID
+	}
+	return c.Before.ID
+}
+
+// DiffTasks returns the changes which turn the before tasks into the after tasks
+func DiffTasks(before, after []Task) []TaskChange {
+	var changes []TaskChange
+
+	afterByID := make(map[string]*Task, len(after))
+	for i := range after {
+		afterByID[after[i].ID] = &after[i]
+	}
+	beforeIDs := make(map[string]bool, len(before))
+	for i := range before {
+		beforeIDs[before[i].ID] = true
+		b := before[i]
+		a, ok := afterByID[b.ID]
+		if !ok {
+			changes = append(changes, TaskChange{Before: &b})
+		} else if !reflect.DeepEqual(b, *a) {
+			a := *a
+			changes = append(changes, TaskChange{Before: &b, After: &a})
+		}
+	}
+	for i := range after {
+		if !beforeIDs[after[i].ID] {
+			a := after[i]
+			changes = append(changes, TaskChange{After: &a})
+		}
+	}
+
+	return changes
+}
+
+// CollectChanges runs op and returns the changes its saves made to the task file.
+// Each save is compared with the tasks it loaded under the lock, so changes which other processes
+// save meanwhile are not included. A task saved several times has a single change.
+func (tf *TaskFile) CollectChanges(op func() error) ([]TaskChange, error) {
+	var changes []TaskChange
+	tf.collected = &changes
+	defer func() { tf.collected = nil }()
+
+	err := op()
+	return changes, err
+}
+
+// collectChanges adds the changes of a save to the ones CollectChanges is collecting, if any
+func (tf *TaskFile) collectChanges(saved []TaskChange) {
+	if tf.collected == nil {
+		return
+	}
+	for _, c := range saved {
+		i := slices.IndexFunc(*tf.collected, func(e TaskChange) bool { return e.taskID() == c.taskID() })
+		if i < 0 {
+			*tf.collected = append(*tf.collected, c)
+			continue
+		}
+		merged := TaskChange{Before: (*tf.collected)[i].Before, After: c.After}
+		if merged.Before == nil && merged.After == nil {
+			// Added and deleted again
+			*tf.collected = slices.Delete(*tf.collected, i, i+1)
+		} else {
+			(*tf.collected)[i] = merged
+		}
+	}
+}
+
+// InvertChanges returns the changes which undo the given changes
+func InvertChanges(changes []TaskChange) []TaskChange {
+	inverted := make([]TaskChange, len(changes))
+	for i, c := range changes {
+		inverted[i] = TaskChange{Before: c.After, After: c.Before}
+	}
+	return inverted
+}
+
+// ApplyTaskChanges applies all changes at once, or none of them if a task has been
+// modified by another process since the Before state was read.
+// Restored tasks get a new Updated time, and the changes as applied are returned.
+func (tf *TaskFile) ApplyTaskChanges(changes []TaskChange) ([]TaskChange, error) {
+	lock, err := tf.lock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock task file: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	tasks, err := tf.LoadTasks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+	// Tasks are replaced below, not changed in place, so a shallow copy keeps them as loaded
+	before := slices.Clone(tasks)
+
+	indexOf := func(id string) int {
+		return slices.IndexFunc(tasks, func(t Task) bool { return t.ID == id })
+	}
+
+	// Check every task first, so that a conflict leaves the file untouched
+	for _, c := range changes {
+		i := indexOf(c.taskID())
+		switch {
+		case c.Before == nil && i >= 0:
+			return nil, fmt.Errorf("task %s already exists", c.taskID())
+		case c.Before != nil && i < 0:
+			return nil, fmt.Errorf("task with ID %s not found", c.taskID())
+		case c.Before != nil && !tasks[i].Updated.Equal(c.Before.Updated):
+			return nil, fmt.Errorf("%w (%v != %v)", ErrTaskModified, tasks[i].Updated, c.Before.Updated)
+		}
+	}
+
+	now := time.Now()
+	applied := make([]TaskChange, 0, len(changes))
+	var deleted []Task
+	restored := make(map[string]bool)
+	for _, c := range changes {
+		i := indexOf(c.taskID())
+		var before *Task
+		if i >= 0 {
+			t := tasks[i]
+			before = &t
+		}
+
+		if c.After == nil {
+			deleted = append(deleted, tasks[i])
+			tasks = slices.Delete(tasks, i, i+1)
+			applied = append(applied, TaskChange{Before: before})
+			continue
+		}
+
+		after := *c.After
+		after.Updated = now
+		if i >= 0 {
+			tasks[i] = after
+		} else {
+			tasks = append(tasks, after)
+			restored[after.ID] = true
+		}
+		applied = append(applied, TaskChange{Before: before, After: &after})
+	}
+
+	if err := tf.saveDeletedTasksToTrash(slices.Clone(deleted)); err != nil {
+		return nil, fmt.Errorf("failed to save deleted tasks to trash: %w", err)
+	}
+	if len(restored) > 0 {
+		// Restored tasks are not in the trash anymore
+		trash, err := tf.loadTrash()
+		if err != nil {
+			return nil, err
+		}
+		kept := slices.DeleteFunc(slices.Clone(trash), func(t Task) bool { return restored[t.ID] })
+		if len(kept) != len(trash) {
+			if err := tf.saveTrash(kept); err != nil {
+				return nil, fmt.Errorf("failed to save trash: %w", err)
+			}
+		}
+	}
+	// Undoing a deletion brings the task back
+	if err := tf.saveTasksAs(before, tasks, JournalRestored, JournalDeleted); err != nil {
+		return nil, fmt.Errorf("failed to save tasks: %w", err)
+	}
+
+	return applied, nil
+}
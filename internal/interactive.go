@@ -1,10 +1,12 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -19,6 +21,7 @@ type InteractiveTaskList struct {
 	tasks             []Task
 	cursor            int
 	showAll           bool
+	archived          map[string]bool // Archived tasks shown with all tasks, which can't be changed
 	quit              bool
 	confirmDelete     bool
 	inputMode         bool
@@ -32,11 +35,32 @@ type InteractiveTaskList struct {
 	dateEditMode      string          // "deadline" or "scheduled"
 	dateEditBuffer    string
 	dateEditCursor    int
-	projectFilter     string // Filter tasks by project
-	projectSelectMode bool   // Mode for selecting project filter
-	projectCursor     int    // Cursor position in project list
-	width             int    // Terminal width
-	height            int    // Terminal height
+	projectFilter     string          // Filter tasks by project
+	projectSelectMode bool            // Mode for selecting project filter
+	projectCursor     int             // Cursor position in project list
+	collapsed         map[string]bool // Tasks whose subtasks are hidden
+	inputParentID     string          // Parent of the task being created in input mode
+	confirmComplete   string          // Parent ID waiting for confirmation to complete its open subtasks
+	views             map[string]View // Saved views from the config file
+	viewName          string          // Current view, empty for none
+	viewSelectMode    bool            // Mode for selecting a view
+	viewCursor        int             // Cursor position in view list
+	fileChanges       <-chan struct{} // Changes of the task file made by other processes
+	stopWatch         context.CancelFunc
+	loadedState       fileState // Version of the task file shown
+	reloadedAt        time.Time // When the task file was reloaded in the background
+	ticking           bool      // Whether a tick refreshing the running timers is scheduled
+	undoStack         []historyEntry
+	redoStack         []historyEntry
+	message           string          // Result of the last undo/redo, shown until the next key
+	trashMode         bool            // Mode for browsing and restoring deleted tasks
+	trash             []Task          // Deleted tasks shown in trash mode
+	trashCursor       int             // Cursor position in trash list
+	agendaMode        bool            // Mode for showing the tasks of the next days by day
+	agendaCursor      int             // Cursor position in the tasks of the agenda
+	conflict          *conflictPrompt // Edit which clashed with changes made elsewhere, waiting for the user
+	width             int             // Terminal width
+	height            int             // Terminal height
 	taskFile          *TaskFile
 	err               error
 }
@@ -63,10 +87,15 @@ func NewInteractiveTaskListWithFilter(taskFile *TaskFile, projectFilter string)
 		projectFilter:     projectFilter,
 		projectSelectMode: false,
 		projectCursor:     0,
+		collapsed:         make(map[string]bool),
 		width:             80, // Default width
 		height:            24, // Default height
 	}
 
+	if config, err := LoadConfig(); err == nil {
+		m.views = config.Views
+	}
+
 	if err := m.ReloadTasks(); err != nil {
 		return nil, fmt.Errorf("failed to load tasks: %w", err)
 	}
@@ -79,28 +108,45 @@ func (m *InteractiveTaskList) ReloadTasks() error {
 		taskID = m.tasks[m.cursor].ID
 	}
 
-	// Sort tasks before displaying
+	// Remember the version of the file, so that the watcher can skip our own saves
+	m.loadedState = m.taskFile.stat()
+
 	tasks, err := m.taskFile.LoadTasks()
 	if err != nil {
 		return fmt.Errorf("failed to load tasks: %w", err)
 	}
-	SortTasks(tasks)
 
-	// Apply project filter if specified
-	var filteredByProject []Task
-	if m.projectFilter != "" {
-		filteredByProject = FilterTasksByProject(tasks, m.projectFilter)
-	} else {
-		filteredByProject = tasks
+	// Showing all tasks includes the archived ones, unless they are back in the task file
+	m.archived = make(map[string]bool)
+	if m.showAll {
+		archived, err := m.taskFile.LoadArchivedTasks()
+		if err != nil {
+			return fmt.Errorf("failed to load archived tasks: %w", err)
+		}
+		current := make(map[string]bool, len(tasks))
+		for _, task := range tasks {
+			current[task.ID] = true
+		}
+		for _, task := range archived {
+			if !current[task.ID] && !m.archived[task.ID] {
+				m.archived[task.ID] = true
+				tasks = append(tasks, task)
+			}
+		}
 	}
 
-	// Then apply a visibility filter
-	m.tasks = FilterVisibleTasks(filteredByProject, false)
+	// Sort tasks before displaying
+	SortTasks(tasks)
+
 	m.allTasks = tasks
+	m.applyFilters()
 
-	// Try to maintain the cursor position on the same task
+	// Try to maintain the cursor position on the same task,
+	// or stay at the same position if the task is gone
+	previousCursor := m.cursor
 	m.cursor = 0
 	if taskID != "" {
+		m.cursor = min(previousCursor, max(len(m.tasks)-1, 0))
 		for j, task := range m.tasks {
 			if task.ID == taskID {
 				m.cursor = j
@@ -117,7 +163,7 @@ func (m *InteractiveTaskList) Init() tea.Cmd {
 		m.err = err
 	}
 
-	return nil
+	return tea.Batch(m.startWatching(), m.scheduleTimerTick())
 }
 
 // truncateTaskLine truncates the task line to fit within the terminal width
@@ -175,18 +221,85 @@ func (m *InteractiveTaskList) stripAnsiCodes(s string) string {
 	return result
 }
 
-// applyFilters applies project filter and visibility filter to tasks
+// applyFilters applies project filter, view and visibility filter to tasks
 func (m *InteractiveTaskList) applyFilters() {
 	// Apply project filter first
 	var filteredByProject []Task
 	if m.projectFilter != "" {
 		filteredByProject = FilterTasksByProject(m.allTasks, m.projectFilter)
 	} else {
-		filteredByProject = m.allTasks
+		filteredByProject = slices.Clone(m.allTasks)
+	}
+
+	// Then apply the view
+	showAll := m.showAll
+	if m.viewName != "" {
+		view := m.views[m.viewName]
+		query, _ := ParseQuery(view.Filter) // Checked by SetView
+		filteredByProject = FilterTasksByQuery(filteredByProject, query)
+		SortTasksBy(filteredByProject, view.Sort)
+		showAll = showAll || view.ShowAll
 	}
 
 	// Then apply visibility filter
-	m.tasks = FilterVisibleTasks(filteredByProject, m.showAll)
+	m.tasks = m.hideCollapsedSubtasks(FilterVisibleTasks(filteredByProject, showAll))
+}
+
+// SetView switches to the saved view with the given name. An empty name shows all tasks.
+func (m *InteractiveTaskList) SetView(name string) error {
+	name = strings.TrimPrefix(name, "@")
+	if name != "" {
+		config := &Config{Views: m.views}
+		if _, err := config.GetView(name); err != nil {
+			return err
+		}
+	}
+
+	m.viewName = name
+	m.applyFilters()
+	if m.cursor >= len(m.tasks) {
+		m.cursor = max(len(m.tasks)-1, 0)
+	}
+	return nil
+}
+
+// hideCollapsedSubtasks removes tasks which have a collapsed ancestor
+func (m *InteractiveTaskList) hideCollapsedSubtasks(tasks []Task) []Task {
+	if len(m.collapsed) == 0 {
+		return tasks
+	}
+
+	parents := make(map[string]string, len(m.allTasks))
+	for _, task := range m.allTasks {
+		parents[task.ID] = task.ParentID
+	}
+
+	var visible []Task
+	for _, task := range tasks {
+		hidden := false
+		seen := map[string]bool{task.ID: true}
+		for parentID := task.ParentID; parentID != "" && !seen[parentID]; parentID = parents[parentID] {
+			if m.collapsed[parentID] {
+				hidden = true
+				break
+			}
+			seen[parentID] = true
+		}
+		if !hidden {
+			visible = append(visible, task)
+		}
+	}
+	return visible
+}
+
+// hasSubtasks reports whether any task has the given task as its parent
+func (m *InteractiveTaskList) hasSubtasks(taskID string) bool {
+	for _, task := range m.allTasks {
+		if task.ParentID == taskID && task.ID != taskID {
+			return true
+		}
+	}
+	return false
 }
 
 // getAvailableProjects returns sorted list of unique projects from all tasks
@@ -208,7 +321,19 @@ func (m *InteractiveTaskList) getAvailableProjects() []string {
 	return projects
 }
 
+// Update handles a message, and keeps the running timers ticking while there are any
 func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	if m.quit {
+		return model, cmd
+	}
+	if tick := m.scheduleTimerTick(); tick != nil {
+		cmd = tea.Batch(cmd, tick)
+	}
+	return model, cmd
+}
+
+func (m *InteractiveTaskList) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		// Update terminal dimensions
@@ -216,7 +341,67 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		return m, nil
 
+	case taskFileChangedMsg:
+		return m, m.handleTaskFileChanged()
+
+	case timerTickMsg:
+		m.ticking = false
+		return m, nil
+
+	case reloadIndicatorExpiredMsg:
+		if time.Since(m.reloadedAt) >= reloadIndicatorDuration {
+			m.reloadedAt = time.Time{}
+		}
+		return m, nil
+
 	case tea.KeyMsg:
+		m.message = ""
+
+		// Handle a conflicting edit first, whatever mode it happened in
+		if m.conflict != nil {
+			return m.updateConflictPrompt(msg)
+		}
+
+		// Handle trash mode
+		if m.trashMode {
+			return m.updateTrashMode(msg)
+		}
+
+		// Handle agenda mode
+		if m.agendaMode {
+			return m.updateAgendaMode(msg)
+		}
+
+		// Handle view select mode
+		if m.viewSelectMode {
+			viewNames := (&Config{Views: m.views}).ViewNames()
+
+			switch msg.String() {
+			case "esc", "q":
+				m.viewSelectMode = false
+				m.viewCursor = 0
+			case "enter":
+				name := ""
+				if m.viewCursor > 0 && m.viewCursor <= len(viewNames) {
+					name = viewNames[m.viewCursor-1]
+				}
+				if err := m.SetView(name); err != nil {
+					m.err = err
+				}
+				m.viewSelectMode = false
+				m.viewCursor = 0
+			case "up", "k":
+				if m.viewCursor > 0 {
+					m.viewCursor--
+				}
+			case "down", "j":
+				if m.viewCursor < len(viewNames) {
+					m.viewCursor++
+				}
+			}
+			return m, nil
+		}
+
 		// Handle project select mode
 		if m.projectSelectMode {
 			projects := m.getAvailableProjects()
@@ -248,6 +433,30 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// Handle confirmation for completing open subtasks
+		if m.confirmComplete != "" {
+			if msg.String() == "y" {
+				if err := m.recordChange("complete subtasks", func() error {
+					for _, subtask := range GetOpenDescendants(m.allTasks, m.confirmComplete) {
+						if err := m.taskFile.UpdateTaskWithMerge(subtask, func(t *Task) {
+							t.SetStatus(StatusDONE)
+						}); err != nil {
+							return err
+						}
+					}
+					return nil
+				}); err != nil {
+					m.err = fmt.Errorf("failed to save task: %w", err)
+				}
+				if err := m.ReloadTasks(); err != nil {
+					m.err = fmt.Errorf("failed to reload tasks: %w", err)
+				}
+			}
+			// Any other key keeps the subtasks open
+			m.confirmComplete = ""
+			return m, tea.ClearScreen
+		}
+
 		// Handle date edit mode
 		if m.dateEditMode != "" {
 			dateRunes := []rune(m.dateEditBuffer)
@@ -257,7 +466,6 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Apply the date change
 				if m.cursor < len(m.tasks) {
 					taskID := m.tasks[m.cursor].ID
-					taskUpdated := m.tasks[m.cursor].Updated
 					for i := range m.allTasks {
 						if m.allTasks[i].ID == taskID {
 							// Parse the date
@@ -274,7 +482,7 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 								}
 							}
 
-							if err := m.taskFile.UpdateTaskWithConflictCheck(taskID, taskUpdated, func(t *Task) {
+							if err := m.saveTask("set "+m.dateEditMode+" date", m.allTasks[i], func(t *Task) {
 								// Update the task
 								switch m.dateEditMode {
 								case "deadline":
@@ -434,6 +642,8 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					// Handle new task creation
 					// Extract projects and scheduled/due dates from title
 					newTask := ParseTask(newTaskTitle)
+					newTask.ParentID = m.inputParentID
+					m.inputParentID = ""
 
 					if err := m.taskFile.AddTask(newTask); err != nil {
 						slog.Error("Failed to create task",
@@ -456,6 +666,7 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.inputMode = false
 				m.inputBuffer = ""
 				m.inputCursor = 0
+				m.inputParentID = ""
 			case tea.KeyCtrlA:
 				// Move to beginning of line
 				m.inputCursor = 0
@@ -553,9 +764,19 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// Archived tasks are only shown, changing them would fail as they aren't in the task file
+		switch msg.String() {
+		case " ", "e", "d", "D", "S", "s", "+", "-", "C":
+			if !m.confirmDelete && m.cursor < len(m.tasks) && m.archived[m.tasks[m.cursor].ID] {
+				m.message = "Archived tasks can't be changed"
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			m.quit = true
+			m.stopWatching()
 			return m, tea.Quit
 
 		case "esc":
@@ -567,6 +788,7 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else {
 				// Otherwise quit
 				m.quit = true
+				m.stopWatching()
 				return m, tea.Quit
 			}
 
@@ -587,7 +809,7 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				task := m.tasks[m.cursor]
 				for i := range m.allTasks {
 					if m.allTasks[i].ID == task.ID {
-						if err := m.taskFile.UpdateTaskWithConflictCheck(task.ID, task.Updated, func(t *Task) {
+						if err := m.saveTask("toggle done", task, func(t *Task) {
 							if t.Status == StatusDONE {
 								t.SetStatus(StatusTODO)
 							} else {
@@ -604,56 +826,45 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							return m, tea.ClearScreen
 						}
 
+						// Offer to complete the open subtasks as well
+						if task.Status != StatusDONE && len(GetOpenDescendants(m.allTasks, task.ID)) > 0 {
+							m.confirmComplete = task.ID
+						}
+
 						break
 					}
 				}
 			}
 
 		case "a":
-			// Toggle show all tasks
+			// Toggle show all tasks, which also loads the archived ones.
+			// ReloadTasks keeps the cursor on the same task.
 			m.showAll = !m.showAll
-			oldCursorTaskID := ""
-			if m.cursor < len(m.tasks) {
-				oldCursorTaskID = m.tasks[m.cursor].ID
-			}
-
-			// Re-sort and filter
-			SortTasks(m.allTasks)
-			m.applyFilters()
-
-			// Try to maintain cursor position on the same task
-			if oldCursorTaskID != "" {
-				for i, task := range m.tasks {
-					if task.ID == oldCursorTaskID {
-						m.cursor = i
-						break
-					}
-				}
-			}
-
-			// Ensure cursor is within bounds
-			if m.cursor >= len(m.tasks) && len(m.tasks) > 0 {
-				m.cursor = len(m.tasks) - 1
+			if err := m.ReloadTasks(); err != nil {
+				m.err = fmt.Errorf("failed to reload tasks: %w", err)
 			}
 
 		case "e":
 			// Edit task (open editor)
 			if m.cursor >= 0 && m.cursor < len(m.tasks) {
 				taskToEdit := &m.tasks[m.cursor]
-				originalUpdated := taskToEdit.Updated
+				original := *taskToEdit
 				if err := editTaskNoteInteractive(taskToEdit); err != nil {
 					m.err = err
 					return m, nil
 				}
 
-				// Update the task with conflict check
-				if err := m.taskFile.UpdateTaskWithConflictCheck(taskToEdit.ID, originalUpdated, func(t *Task) {
+				// Merge the edit with changes made while the editor was open
+				if err := m.saveTask("edit task", original, func(t *Task) {
 					t.Title = taskToEdit.Title
 					t.Projects = taskToEdit.Projects
 					t.Note = taskToEdit.Note
 				}); err != nil {
 					m.err = fmt.Errorf("failed to save task: %w", err)
 				}
+				if err := m.ReloadTasks(); err != nil {
+					m.err = fmt.Errorf("failed to reload tasks: %w", err)
+				}
 				return m, nil
 			}
 
@@ -697,7 +908,9 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Mark task as deleted
 				taskID := m.tasks[m.cursor].ID
 
-				if err := m.taskFile.DeleteTask(taskID); err != nil {
+				if err := m.recordChange("delete task", func() error {
+					return m.taskFile.DeleteTask(taskID)
+				}); err != nil {
 					m.err = fmt.Errorf("failed to delete task: %w", err)
 					m.confirmDelete = false
 					return m, tea.ClearScreen
@@ -753,6 +966,34 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.inputMode = true
 				m.inputBuffer = ""
 				m.inputCursor = 0
+				m.inputParentID = ""
+			}
+
+		case "C":
+			// Create new subtask of the current task
+			if !m.confirmDelete && m.cursor < len(m.tasks) {
+				m.inputMode = true
+				m.inputBuffer = ""
+				m.inputCursor = 0
+				m.inputParentID = m.tasks[m.cursor].ID
+			}
+
+		case "z":
+			// Collapse or expand subtasks of the current task
+			if !m.confirmDelete && m.cursor < len(m.tasks) {
+				taskID := m.tasks[m.cursor].ID
+				if m.collapsed[taskID] {
+					delete(m.collapsed, taskID)
+				} else if m.hasSubtasks(taskID) {
+					m.collapsed[taskID] = true
+				}
+				m.applyFilters()
+				for i, task := range m.tasks {
+					if task.ID == taskID {
+						m.cursor = i
+						break
+					}
+				}
 			}
 
 		case "r":
@@ -768,6 +1009,30 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.ClearScreen
 			}
 
+		case "u":
+			// Undo the last change
+			if !m.confirmDelete && !m.inputMode {
+				m.undo()
+			}
+
+		case "ctrl+r":
+			// Redo the last undone change
+			if !m.confirmDelete && !m.inputMode {
+				m.redo()
+			}
+
+		case "T":
+			// Show deleted tasks
+			if !m.confirmDelete && !m.inputMode {
+				m.openTrash()
+			}
+
+		case "A":
+			// Show the agenda of the next days
+			if !m.confirmDelete && !m.inputMode {
+				m.openAgenda()
+			}
+
 		case "p":
 			// Enter project select mode
 			if !m.confirmDelete && !m.inputMode {
@@ -785,6 +1050,20 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+		case "v":
+			// Enter view select mode
+			if !m.confirmDelete && !m.inputMode {
+				m.viewSelectMode = true
+				m.viewCursor = 0
+				// Find current view in list
+				for i, name := range (&Config{Views: m.views}).ViewNames() {
+					if name == m.viewName {
+						m.viewCursor = i + 1 // +1 because 0 is "No view"
+						break
+					}
+				}
+			}
+
 		case "s":
 			// Cycle through statuses
 			if !m.confirmDelete && !m.inputMode && m.cursor < len(m.tasks) {
@@ -805,7 +1084,7 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				// Cycle to next status
 				nextIdx := (currentIdx + 1) % len(allStatuses)
-				if err := m.taskFile.UpdateTaskWithConflictCheck(task.ID, task.Updated, func(t *Task) {
+				if err := m.saveTask("change status", task, func(t *Task) {
 					t.SetStatus(allStatuses[nextIdx])
 				}); err != nil {
 					m.err = fmt.Errorf("failed to save task: %w", err)
@@ -831,7 +1110,7 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				if taskIdx >= 0 {
-					if err := m.taskFile.UpdateTaskWithConflictCheck(m.allTasks[taskIdx].ID, m.allTasks[taskIdx].Updated, func(t *Task) {
+					if err := m.saveTask("raise priority", m.allTasks[taskIdx], func(t *Task) {
 						t.IncreasePriority()
 					}); err != nil {
 						m.err = fmt.Errorf("failed to save task: %w", err)
@@ -858,7 +1137,7 @@ func (m *InteractiveTaskList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				if taskIdx >= 0 {
-					if err := m.taskFile.UpdateTaskWithConflictCheck(m.allTasks[taskIdx].ID, m.allTasks[taskIdx].Updated, func(t *Task) {
+					if err := m.saveTask("lower priority", m.allTasks[taskIdx], func(t *Task) {
 						t.DecreasePriority()
 					}); err != nil {
 						m.err = fmt.Errorf("failed to save task: %w", err)
@@ -882,7 +1161,14 @@ func (m *InteractiveTaskList) View() string {
 		return ""
 	}
 
+	if m.agendaMode {
+		return lipgloss.NewStyle().MaxWidth(m.width).Render(m.renderAgenda())
+	}
+
 	if len(m.tasks) == 0 {
+		if m.trashMode {
+			return "No tasks found." + m.renderFooter()
+		}
 		return "No tasks found.\n\nPress q to quit."
 	}
 
@@ -893,6 +1179,9 @@ func (m *InteractiveTaskList) View() string {
 
 	s.WriteString(header)
 
+	depths := TaskDepths(m.tasks)
+	openBlockers := CountOpenBlockers(m.allTasks)
+
 	for i, task := range m.tasks {
 		cursor := "  "
 		if m.cursor == i {
@@ -971,6 +1260,18 @@ func (m *InteractiveTaskList) View() string {
 			}
 		}
 
+		if task.Recurrence != "" {
+			additionalInfo += fmt.Sprintf(" \x1b[90m(repeat %s)\x1b[0m", task.Recurrence)
+		}
+
+		if effort := task.DisplayEffort(); effort != "" {
+			additionalInfo += fmt.Sprintf(" \x1b[90m[%s]\x1b[0m", effort)
+		}
+
+		if count := openBlockers[task.ID]; count > 0 {
+			additionalInfo += fmt.Sprintf(" \x1b[35m(blocked by %d)\x1b[0m", count)
+		}
+
 		// Build the complete line with truncation
 		// First build projects string with colors
 		projectsStr := ""
@@ -981,8 +1282,16 @@ func (m *InteractiveTaskList) View() string {
 			}
 		}
 
+		// Indent subtasks under their parent and mark tasks which have subtasks
+		title := strings.Repeat("  ", depths[task.ID]) + task.Title
+		if m.collapsed[task.ID] {
+			title = strings.Repeat("  ", depths[task.ID]) + "▸ " + task.Title
+		} else if m.hasSubtasks(task.ID) {
+			title = strings.Repeat("  ", depths[task.ID]) + "▾ " + task.Title
+		}
+
 		// Use truncate function to build the line with all components
-		line = m.truncateTaskLine(cursor, statusColor, status, priority, task.Title, task.Projects, additionalInfo)
+		line = m.truncateTaskLine(cursor, statusColor, status, priority, title, task.Projects, additionalInfo)
 
 		// Add projects and additional info (already accounted for in truncation calculation)
 		line += projectsStr
@@ -999,6 +1308,10 @@ func (m *InteractiveTaskList) View() string {
 
 func (m *InteractiveTaskList) renderHeader() string {
 	var s strings.Builder
+	s.WriteString(m.renderTimers())
+	if m.viewName != "" {
+		s.WriteString(fmt.Sprintf("View: @%s \x1b[90m%s\x1b[0m\n", m.viewName, m.views[m.viewName].Filter))
+	}
 	if m.projectFilter != "" {
 		// Show project filter with color and count
 		projectColor := GetProjectColor(m.projectFilter)
@@ -1083,7 +1396,18 @@ func (m *InteractiveTaskList) renderFooter() string {
 			displayStr = string(runes[:m.inputCursor]) + "_" + string(runes[m.inputCursor:])
 		}
 
-		s.WriteString("\n\n📝 New task title: " + displayStr)
+		if m.inputParentID != "" {
+			parentTitle := ""
+			for _, task := range m.allTasks {
+				if task.ID == m.inputParentID {
+					parentTitle = task.Title
+					break
+				}
+			}
+			s.WriteString(fmt.Sprintf("\n\n📝 New subtask of %q: %s", parentTitle, displayStr))
+		} else {
+			s.WriteString("\n\n📝 New task title: " + displayStr)
+		}
 		s.WriteString("\n\nEnter: create • Esc: cancel • Tab: complete project • Ctrl+A/E: begin/end • Ctrl+F/B: move • Ctrl+H: backspace • Ctrl+K: kill • Ctrl+D: delete")
 	} else if m.projectSelectMode {
 		// Show project selection UI
@@ -1117,6 +1441,40 @@ func (m *InteractiveTaskList) renderFooter() string {
 		}
 
 		s.WriteString("\n↑/k: up • ↓/j: down • Enter: select • Esc/q: cancel")
+	} else if m.trashMode {
+		m.renderTrash(&s)
+	} else if m.viewSelectMode {
+		// Show view selection UI
+		s.WriteString("\n\n🔖 Select view:\n\n")
+
+		cursor := "  "
+		if m.viewCursor == 0 {
+			cursor = "> "
+		}
+		s.WriteString(cursor + "[No view]\n")
+
+		viewNames := (&Config{Views: m.views}).ViewNames()
+		for i, name := range viewNames {
+			cursor := "  "
+			if i+1 == m.viewCursor {
+				cursor = "> "
+			}
+			s.WriteString(fmt.Sprintf("%s@%s \x1b[90m%s\x1b[0m\n", cursor, name, m.views[name].Filter))
+		}
+		if len(viewNames) == 0 {
+			s.WriteString("  \x1b[90m(define views in the [views.<name>] sections of config.toml)\x1b[0m\n")
+		}
+
+		s.WriteString("\n↑/k: up • ↓/j: down • Enter: select • Esc/q: cancel")
+	} else if m.conflict != nil {
+		s.WriteString(m.renderConflictPrompt())
+	} else if m.confirmComplete != "" {
+		openCount := len(GetOpenDescendants(m.allTasks, m.confirmComplete))
+		s.WriteString(fmt.Sprintf("\n\n✅ Also complete %d open subtask", openCount))
+		if openCount != 1 {
+			s.WriteString("s")
+		}
+		s.WriteString("? (y/n)")
 	} else if m.confirmDelete {
 		s.WriteString(fmt.Sprintf("\n\n⚠️  Delete this task? (y/n): %s", m.tasks[m.cursor].Title))
 	} else {
@@ -1124,10 +1482,16 @@ func (m *InteractiveTaskList) renderFooter() string {
 		if m.searchQuery != "" && !m.searchMode {
 			s.WriteString(" • n/N: next/prev match • ESC: clear search")
 		}
-		s.WriteString(" • a: all • c: create • e: edit • d: delete • p: projects • r: reload • q: quit")
+		s.WriteString(" • a: all • c: create • C: subtask • z: fold • e: edit • d: delete • u/ctrl+r: undo/redo • T: trash • A: agenda • p: projects • v: views • r: reload • q: quit")
 		if m.showAll {
 			s.WriteString(" [ALL]")
 		}
+		if !m.reloadedAt.IsZero() {
+			s.WriteString(" \x1b[90m↻ reloaded\x1b[0m")
+		}
+		if m.message != "" {
+			s.WriteString("\n\n" + m.message)
+		}
 	}
 
 	if m.err != nil {
@@ -1145,24 +1509,16 @@ func (m *InteractiveTaskList) updateMatches() {
 		return
 	}
 
-	query := strings.ToLower(m.searchQuery)
-
-	for _, task := range m.tasks {
-		// Search in title, projects, and note
-		titleMatch := strings.Contains(strings.ToLower(task.Title), query)
-		noteMatch := strings.Contains(strings.ToLower(task.Note), query)
-
-		// Check projects
-		projectMatch := false
-		for _, project := range task.Projects {
-			if strings.Contains(strings.ToLower(project), query) {
-				projectMatch = true
-				break
-			}
-		}
+	// The search box accepts the same filter expressions as `taskeru ls`
+	query, err := ParseQuery(m.searchQuery)
+	if err != nil || query.IsEmpty() {
+		// Incomplete expressions (e.g. "due:<") simply match nothing while typing
+		return
+	}
 
-		if titleMatch || noteMatch || projectMatch {
-			m.matchingTasks[task.ID] = true
+	for i := range m.tasks {
+		if query.Match(&m.tasks[i]) {
+			m.matchingTasks[m.tasks[i].ID] = true
 		}
 	}
 }
@@ -0,0 +1,287 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// agendaDays is how many days, starting today, the agenda shows
+const agendaDays = 14
+
+// agendaSection is a group of tasks in the agenda, either the overdue tasks or the tasks of a day
+type agendaSection struct {
+	overdue bool
+	day     time.Time
+	tasks   []Task
+}
+
+func (s agendaSection) title(today time.Time) string {
+	switch {
+	case s.overdue:
+		return "Overdue"
+	case s.day.Equal(today):
+		return "Today " + s.day.Format("Mon 01-02")
+	case s.day.Equal(today.AddDate(0, 0, 1)):
+		return "Tomorrow " + s.day.Format("Mon 01-02")
+	default:
+		return s.day.Format("Mon 01-02")
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// agendaDate returns the date which places an open task in the agenda:
+// the scheduled date if there is one, otherwise the due date
+func agendaDate(task Task) *time.Time {
+	if task.ScheduledDate != nil {
+		return task.ScheduledDate
+	}
+	return task.DueDate
+}
+
+// buildAgenda groups open tasks by the day they are scheduled or due, for the given number of days
+// starting today. Tasks past their due date are overdue, tasks scheduled in the past are shown today.
+// Every day gets a section, even without tasks, so that the agenda reads like a calendar.
+func buildAgenda(tasks []Task, today time.Time, days int) []agendaSection {
+	today = startOfDay(today)
+	overdue := agendaSection{overdue: true}
+	sections := make([]agendaSection, days)
+	for i := range sections {
+		sections[i].day = today.AddDate(0, 0, i)
+	}
+
+	for _, task := range tasks {
+		if task.IsCompleted() {
+			continue
+		}
+		if task.DueDate != nil && task.DueDate.Before(today) {
+			overdue.tasks = append(overdue.tasks, task)
+			continue
+		}
+		date := agendaDate(task)
+		if date == nil {
+			continue
+		}
+		day := maxTime(startOfDay(*date), today)
+		for i := range sections {
+			if sections[i].day.Equal(day) {
+				sections[i].tasks = append(sections[i].tasks, task)
+				break
+			}
+		}
+	}
+
+	if len(overdue.tasks) > 0 {
+		sections = append([]agendaSection{overdue}, sections...)
+	}
+	return sections
+}
+
+// agendaTasks returns the tasks of the agenda in display order
+func agendaTasks(sections []agendaSection) []Task {
+	var tasks []Task
+	for _, section := range sections {
+		tasks = append(tasks, section.tasks...)
+	}
+	return tasks
+}
+
+// openAgenda shows the tasks of the next days grouped by day
+func (m *InteractiveTaskList) openAgenda() {
+	m.agendaMode = true
+	m.agendaCursor = 0
+}
+
+// agenda returns the agenda of the tasks shown in the list, so that project and view filters apply
+func (m *InteractiveTaskList) agenda() []agendaSection {
+	return buildAgenda(m.tasks, time.Now(), agendaDays)
+}
+
+// updateAgendaMode handles keys in the agenda view
+func (m *InteractiveTaskList) updateAgendaMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tasks := agendaTasks(m.agenda())
+
+	switch msg.String() {
+	case "ctrl+c":
+		m.stopWatching()
+		m.quit = true
+		return m, tea.Quit
+	case "esc", "q", "A":
+		m.agendaMode = false
+	case "up", "k":
+		if m.agendaCursor > 0 {
+			m.agendaCursor--
+		}
+	case "down", "j":
+		if m.agendaCursor < len(tasks)-1 {
+			m.agendaCursor++
+		}
+	case "shift+down":
+		m.moveAgendaTask(tasks, 1)
+	case "shift+up":
+		m.moveAgendaTask(tasks, -1)
+	case "shift+right":
+		m.moveAgendaTask(tasks, 7)
+	case "shift+left":
+		m.moveAgendaTask(tasks, -7)
+	case "u":
+		m.undo()
+	case "ctrl+r":
+		m.redo()
+	case "enter":
+		// Show the task in the list
+		if m.agendaCursor < len(tasks) {
+			m.agendaMode = false
+			for i, task := range m.tasks {
+				if task.ID == tasks[m.agendaCursor].ID {
+					m.cursor = i
+					break
+				}
+			}
+		}
+	}
+
+	m.agendaCursor = min(m.agendaCursor, max(len(agendaTasks(m.agenda()))-1, 0))
+	return m, nil
+}
+
+// moveAgendaTask moves the task under the cursor by the given number of days.
+// The date which places the task in the agenda is moved, keeping its time of day.
+// Tasks can't be moved before today, and moving an overdue task later makes it due today at the earliest.
+func (m *InteractiveTaskList) moveAgendaTask(tasks []Task, days int) {
+	if m.agendaCursor >= len(tasks) {
+		return
+	}
+	task := tasks[m.agendaCursor]
+	today := startOfDay(time.Now())
+
+	// Overdue tasks are moved from their due date, others from the day they are shown
+	overdue := task.DueDate != nil && task.DueDate.Before(today)
+	moveScheduled := !overdue && task.ScheduledDate != nil
+	from := maxTime(startOfDay(*agendaDate(task)), today)
+	if overdue {
+		from = startOfDay(*task.DueDate)
+	}
+	to := from.AddDate(0, 0, days)
+	if days > 0 && to.Before(today) {
+		to = today
+	}
+	if to.Before(today) {
+		m.message = "Can't move a task into the past"
+		return
+	}
+
+	if err := m.saveTask("move task", task, func(t *Task) {
+		if moveScheduled {
+			scheduled := moveToDay(*t.ScheduledDate, to)
+			t.ScheduledDate = &scheduled
+			// A task can't be due before it starts
+			if t.DueDate != nil && t.DueDate.Before(scheduled) {
+				due := moveToDay(*t.DueDate, to)
+				t.DueDate = &due
+			}
+		} else {
+			due := moveToDay(*t.DueDate, to)
+			t.DueDate = &due
+		}
+	}); err != nil {
+		m.err = fmt.Errorf("failed to move task: %w", err)
+		return
+	}
+	if m.conflict == nil {
+		m.message = fmt.Sprintf("Moved %s to %s", task.Title, to.Format("Mon 01-02"))
+	}
+
+	if err := m.ReloadTasks(); err != nil {
+		m.err = fmt.Errorf("failed to reload tasks: %w", err)
+		return
+	}
+
+	// Keep the cursor on the moved task
+	for i, t := range agendaTasks(m.agenda()) {
+		if t.ID == task.ID {
+			m.agendaCursor = i
+			break
+		}
+	}
+}
+
+// moveToDay returns t on the given day, keeping the time of day
+func moveToDay(t time.Time, day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+func (m *InteractiveTaskList) renderAgenda() string {
+	var lines []string
+	cursorLine := 0
+	today := startOfDay(time.Now())
+
+	index := 0
+	for _, section := range m.agenda() {
+		titleColor := "\x1b[1m"
+		switch {
+		case section.overdue:
+			titleColor = "\x1b[1;91m"
+		case section.day.Weekday() == time.Saturday || section.day.Weekday() == time.Sunday:
+			titleColor = "\x1b[1;90m"
+		}
+		title := fmt.Sprintf("%s%s\x1b[0m", titleColor, section.title(today))
+		if effort := SumEffort(section.tasks).String(); effort != "" {
+			title += fmt.Sprintf(" \x1b[90m[%s]\x1b[0m", effort)
+		}
+		lines = append(lines, title)
+
+		for _, task := range section.tasks {
+			cursor := "  "
+			if index == m.agendaCursor {
+				cursor = "> "
+				cursorLine = len(lines)
+			}
+
+			var info string
+			if task.ScheduledDate != nil && task.DueDate != nil && !section.overdue {
+				info = fmt.Sprintf(" \x1b[90m(due %s)\x1b[0m", task.DueDate.Format("01-02"))
+			} else if section.overdue {
+				info = fmt.Sprintf(" \x1b[91m(due %s)\x1b[0m", task.DueDate.Format("01-02"))
+			}
+			for _, project := range task.Projects {
+				info += fmt.Sprintf(" %s+%s\x1b[0m", GetProjectColor(project), project)
+			}
+
+			lines = append(lines, fmt.Sprintf("%s%-7s %s %s%s",
+				cursor, task.DisplayStatus(), task.DisplayPriority(), task.Title, info))
+			index++
+		}
+	}
+
+	var s strings.Builder
+	s.WriteString(m.renderTimers())
+	s.WriteString(fmt.Sprintf("📅 Agenda (next %d days)", agendaDays))
+	if m.projectFilter != "" {
+		s.WriteString(fmt.Sprintf(" for project: %s+%s\x1b[0m", GetProjectColor(m.projectFilter), m.projectFilter))
+	}
+	s.WriteString("\n\n")
+
+	// Scroll so that the cursor stays visible
+	footer := "\n↑/k: up • ↓/j: down • shift+↓/↑: move a day • shift+→/←: move a week • Enter: show in list • u/ctrl+r: undo/redo • Esc/q: close"
+	if m.message != "" {
+		footer += "\n\n" + m.message
+	}
+	visible := max(m.height-strings.Count(s.String(), "\n")-strings.Count(footer, "\n")-2, 5)
+	start := max(0, min(cursorLine-visible/2, len(lines)-visible))
+	end := min(len(lines), start+visible)
+	for _, line := range lines[start:end] {
+		s.WriteString(line + "\n")
+	}
+	s.WriteString(footer)
+
+	if m.err != nil {
+		s.WriteString("\n\n\x1b[91mError: " + m.err.Error() + "\x1b[0m\n")
+	}
+	return s.String()
+}
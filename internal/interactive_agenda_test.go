@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildAgenda(t *testing.T) {
+	today := time.Date(2025, 3, 10, 15, 0, 0, 0, time.Local)
+	day := func(offset int, hour int) *time.Time {
+		d := time.Date(2025, 3, 10+offset, hour, 0, 0, 0, time.Local)
+		return &d
+	}
+
+	tasks := []Task{
+		{ID: "overdue", Status: StatusTODO, DueDate: day(-2, 23)},
+		{ID: "today", Status: StatusTODO, DueDate: day(0, 23)},
+		{ID: "carried", Status: StatusTODO, ScheduledDate: day(-3, 0)},
+		{ID: "tomorrow", Status: StatusTODO, ScheduledDate: day(1, 0), DueDate: day(5, 23)},
+		{ID: "later", Status: StatusTODO, DueDate: day(13, 23)},
+		{ID: "too-late", Status: StatusTODO, DueDate: day(14, 23)},
+		{ID: "done", Status: StatusDONE, DueDate: day(0, 23)},
+		{ID: "undated", Status: StatusTODO},
+	}
+
+	sections := buildAgenda(tasks, today, 14)
+	require.Len(t, sections, 15)
+
+	ids := func(section agendaSection) []string {
+		var ids []string
+		for _, task := range section.tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+	startOfToday := startOfDay(today)
+	require.True(t, sections[0].overdue)
+	require.Equal(t, "Overdue", sections[0].title(startOfToday))
+	require.Equal(t, []string{"overdue"}, ids(sections[0]))
+	require.Equal(t, "Today Mon 03-10", sections[1].title(startOfToday))
+	require.Equal(t, []string{"today", "carried"}, ids(sections[1]))
+	require.Equal(t, "Tomorrow Tue 03-11", sections[2].title(startOfToday))
+	require.Equal(t, []string{"tomorrow"}, ids(sections[2]))
+	require.Empty(t, sections[3].tasks)
+	require.Equal(t, []string{"later"}, ids(sections[14]))
+
+	// Without overdue tasks there is no overdue section
+	require.Len(t, buildAgenda(tasks[1:], today, 7), 7)
+}
+
+func TestAgendaMovesTasks(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	today := startOfDay(time.Now())
+
+	scheduled := today.AddDate(0, 0, 1)
+	due := today.AddDate(0, 0, 1).Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+	task := NewTask("Plan the week")
+	task.ScheduledDate = &scheduled
+	task.DueDate = &due
+
+	overdueDate := today.AddDate(0, 0, -3).Add(23 * time.Hour)
+	overdue := NewTask("Pay the bills")
+	overdue.DueDate = &overdueDate
+	require.NoError(t, taskFile.AddTasks([]Task{*task, *overdue}))
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+
+	pressKey(t, model, "A")
+	require.True(t, model.agendaMode)
+	require.Contains(t, model.View(), "Overdue")
+
+	// The overdue task comes first, and moving it later makes it due today at the earliest
+	model.Update(tea.KeyMsg{Type: tea.KeyShiftDown})
+	require.WithinDuration(t, today.Add(23*time.Hour), *loadTaskByID(t, taskFile, overdue.ID).DueDate, 0)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyShiftRight})
+	require.WithinDuration(t, today.AddDate(0, 0, 7).Add(23*time.Hour), *loadTaskByID(t, taskFile, overdue.ID).DueDate, 0)
+	require.Contains(t, model.renderAgenda(), "Moved Pay the bills")
+
+	// The scheduled task is moved by its scheduled date, and takes the due date along
+	model.Update(tea.KeyMsg{Type: tea.KeyUp})
+	model.Update(tea.KeyMsg{Type: tea.KeyShiftDown})
+	moved := loadTaskByID(t, taskFile, task.ID)
+	require.WithinDuration(t, today.AddDate(0, 0, 2), *moved.ScheduledDate, 0)
+	require.WithinDuration(t, today.AddDate(0, 0, 2).Add(23*time.Hour+59*time.Minute+59*time.Second), *moved.DueDate, 0)
+
+	// Tasks can't be moved before today
+	model.Update(tea.KeyMsg{Type: tea.KeyShiftLeft})
+	require.WithinDuration(t, today.AddDate(0, 0, 2), *loadTaskByID(t, taskFile, task.ID).ScheduledDate, 0)
+	require.Contains(t, model.renderAgenda(), "Can't move a task into the past")
+
+	// Moves can be undone
+	pressKey(t, model, "u")
+	require.WithinDuration(t, scheduled, *loadTaskByID(t, taskFile, task.ID).ScheduledDate, 0)
+
+	pressKey(t, model, "q")
+	require.False(t, model.agendaMode)
+	require.False(t, model.quit)
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// conflictPrompt is an edit which clashed with changes another process made to the same fields.
+// The user decides whether to keep their values or the other ones.
+type conflictPrompt struct {
+	description string
+	conflict    *ConflictError
+	base        Task // The version the edit started from
+	edited      Task // base with the edit applied
+}
+
+// saveTask applies updateFunc to original, the version of the task the change started from, merged
+// with changes made meanwhile and recorded for undo. A conflict doesn't return an error, but asks
+// the user how to resolve it.
+func (m *InteractiveTaskList) saveTask(description string, original Task, updateFunc func(*Task)) error {
+	err := m.recordChange(description, func() error {
+		return m.taskFile.UpdateTaskWithMerge(original, updateFunc)
+	})
+
+	var conflict *ConflictError
+	if errors.As(err, &conflict) {
+		edited := cloneTask(original)
+		updateFunc(&edited)
+		m.conflict = &conflictPrompt{description: description, conflict: conflict, base: original, edited: edited}
+		return nil
+	}
+	return err
+}
+
+// updateConflictPrompt handles the keys while a conflict is shown:
+// y keeps the values of the edit, any other key keeps the values saved by the other process
+func (m *InteractiveTaskList) updateConflictPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	prompt := m.conflict
+	m.conflict = nil
+
+	if msg.String() == "y" {
+		// The edit goes on top of the current version now. If the task changed yet again, this asks again.
+		if err := m.saveTask(prompt.description, prompt.conflict.Current, func(t *Task) {
+			applyChangedFields(t, prompt.base, prompt.edited)
+		}); err != nil {
+			m.err = fmt.Errorf("failed to save task: %w", err)
+		} else if m.conflict == nil {
+			m.message = fmt.Sprintf("Kept your %s", strings.Join(prompt.conflict.Fields, ", "))
+		}
+	} else {
+		m.message = "Kept the changes made elsewhere"
+	}
+
+	if err := m.ReloadTasks(); err != nil {
+		m.err = fmt.Errorf("failed to reload tasks: %w", err)
+	}
+	return m, tea.ClearScreen
+}
+
+// renderConflictPrompt returns the question shown for a conflict
+func (m *InteractiveTaskList) renderConflictPrompt() string {
+	return fmt.Sprintf("\n\n⚠️  %q was also changed elsewhere: %s. Keep your changes? (y/n)",
+		m.conflict.conflict.Current.Title, strings.Join(m.conflict.conflict.Fields, ", "))
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInteractiveMergesConcurrentEdits(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	task := NewTask("Shared task")
+	require.NoError(t, taskFile.AddTask(task))
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+
+	// changeElsewhere updates the task behind the back of the model
+	changeElsewhere := func(updateFunc func(*Task)) {
+		t.Helper()
+		current := loadTaskByID(t, taskFile, task.ID)
+		require.NoError(t, taskFile.UpdateTaskWithConflictCheck(task.ID, current.Updated, updateFunc))
+	}
+
+	// Different fields are merged without asking
+	changeElsewhere(func(t *Task) { t.Note = "Changed elsewhere" })
+	pressKey(t, model, "+")
+	require.Nil(t, model.conflict)
+	saved := loadTaskByID(t, taskFile, task.ID)
+	require.Equal(t, "C", saved.Priority)
+	require.Equal(t, "Changed elsewhere", saved.Note)
+
+	// The same field asks, and n keeps the other value
+	changeElsewhere(func(t *Task) { t.Priority = "A" })
+	pressKey(t, model, "+")
+	require.NotNil(t, model.conflict)
+	require.Contains(t, model.View(), `"Shared task" was also changed elsewhere: priority`)
+	pressKey(t, model, "n")
+	require.Nil(t, model.conflict)
+	require.Equal(t, "A", loadTaskByID(t, taskFile, task.ID).Priority)
+	require.Contains(t, model.renderFooter(), "Kept the changes made elsewhere")
+
+	// y keeps the value of the edit, and leaves the other fields alone
+	changeElsewhere(func(t *Task) {
+		t.Priority = "C"
+		t.Note = "Changed elsewhere again"
+	})
+	pressKey(t, model, "-")
+	require.NotNil(t, model.conflict)
+	pressKey(t, model, "y")
+	require.Nil(t, model.conflict)
+	saved = loadTaskByID(t, taskFile, task.ID)
+	require.Equal(t, "B", saved.Priority)
+	require.Equal(t, "Changed elsewhere again", saved.Note)
+	require.Contains(t, model.renderFooter(), "Kept your priority")
+}
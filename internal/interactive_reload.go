@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// taskFileWatchInterval is how often the TUI checks the task file for changes
+	taskFileWatchInterval = 500 * time.Millisecond
+	// reloadIndicatorDuration is how long "reloaded" is shown in the footer
+	reloadIndicatorDuration = 3 * time.Second
+	// reloadRetryDelay is how long a reload waits while a prompt is open
+	reloadRetryDelay = 500 * time.Millisecond
+)
+
+// taskFileChangedMsg is sent when the task file was changed on disk
+type taskFileChangedMsg struct{}
+
+// reloadIndicatorExpiredMsg hides the "reloaded" indicator
+type reloadIndicatorExpiredMsg struct{}
+
+// startWatching starts watching the task file for changes by other processes
+func (m *InteractiveTaskList) startWatching() tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.stopWatch = cancel
+	m.fileChanges = m.taskFile.Watch(ctx, taskFileWatchInterval)
+	return m.waitForTaskFileChange()
+}
+
+func (m *InteractiveTaskList) stopWatching() {
+	if m.stopWatch != nil {
+		m.stopWatch()
+	}
+}
+
+// waitForTaskFileChange returns a command which delivers the next change of the task file
+func (m *InteractiveTaskList) waitForTaskFileChange() tea.Cmd {
+	changes := m.fileChanges
+	if changes == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		if _, ok := <-changes; !ok {
+			return nil
+		}
+		return taskFileChangedMsg{}
+	}
+}
+
+// handleTaskFileChanged reloads the tasks after the file was changed by another process
+func (m *InteractiveTaskList) handleTaskFileChanged() tea.Cmd {
+	// Our own saves are followed by ReloadTasks, so there is nothing new to show
+	if m.taskFile.stat().equal(m.loadedState) {
+		return m.waitForTaskFileChange()
+	}
+
+	// Don't swap the task under an open prompt; try again once it is closed
+	if m.confirmDelete || m.confirmComplete != "" || m.dateEditMode != "" {
+		return tea.Tick(reloadRetryDelay, func(time.Time) tea.Msg {
+			return taskFileChangedMsg{}
+		})
+	}
+
+	if err := m.ReloadTasks(); err != nil {
+		m.err = fmt.Errorf("failed to reload tasks: %w", err)
+		return m.waitForTaskFileChange()
+	}
+	if m.searchQuery != "" {
+		m.updateMatches()
+	}
+
+	m.reloadedAt = time.Now()
+	return tea.Batch(
+		m.waitForTaskFileChange(),
+		tea.Tick(reloadIndicatorDuration, func(time.Time) tea.Msg {
+			return reloadIndicatorExpiredMsg{}
+		}),
+	)
+}
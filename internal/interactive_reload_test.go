@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInteractiveReloadsOnFileChange(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	first := NewTask("First task")
+	second := NewTask("Second task")
+	require.NoError(t, taskFile.AddTasks([]Task{*first, *second}))
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+
+	// Put the cursor on the second task
+	for i, task := range model.tasks {
+		if task.ID == second.ID {
+			model.cursor = i
+		}
+	}
+
+	// No change yet, so nothing is reloaded
+	model.Update(taskFileChangedMsg{})
+	require.True(t, model.reloadedAt.IsZero())
+
+	// Another process adds a task
+	require.NoError(t, taskFile.AddTask(NewTask("Added elsewhere")))
+	model.Update(taskFileChangedMsg{})
+
+	require.Len(t, model.tasks, 3)
+	require.Equal(t, second.ID, model.tasks[model.cursor].ID, "cursor should stay on the same task")
+	require.Contains(t, model.renderFooter(), "reloaded")
+
+	model.reloadedAt = model.reloadedAt.Add(-reloadIndicatorDuration)
+	model.Update(reloadIndicatorExpiredMsg{})
+	require.NotContains(t, model.renderFooter(), "reloaded")
+}
+
+func TestInteractiveReloadWaitsForOpenPrompt(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	require.NoError(t, taskFile.AddTask(NewTask("First task")))
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+	model.confirmDelete = true
+
+	require.NoError(t, taskFile.AddTask(NewTask("Added elsewhere")))
+	_, cmd := model.Update(taskFileChangedMsg{})
+
+	require.NotNil(t, cmd, "reload should be retried later")
+	require.Len(t, model.tasks, 1)
+}
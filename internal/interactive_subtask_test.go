@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+)
+
+func newSubtaskTestModel(t *testing.T) (*InteractiveTaskList, *TaskFile, *Task) {
+	parent := NewTask("Parent task")
+	parent.Priority = "A"
+	child1 := NewTask("Child 1")
+	child1.ParentID = parent.ID
+	child2 := NewTask("Child 2")
+	child2.ParentID = parent.ID
+
+	taskFile := NewTaskFileForTesting(t)
+	require.NoError(t, taskFile.AddTasks([]Task{*child1, *parent, *child2}))
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+	return model, taskFile, parent
+}
+
+func TestInteractiveSubtaskIndentAndCollapse(t *testing.T) {
+	model, _, parent := newSubtaskTestModel(t)
+
+	require.Len(t, model.tasks, 3)
+	require.Equal(t, parent.ID, model.tasks[0].ID)
+
+	view := model.View()
+	require.Contains(t, view, "▾ Parent task")
+	require.Contains(t, view, "  Child 1")
+
+	// Collapse
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}})
+	require.Len(t, model.tasks, 1)
+	require.Contains(t, model.View(), "▸ Parent task")
+	require.NotContains(t, model.View(), "Child 1")
+
+	// Expand
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}})
+	require.Len(t, model.tasks, 3)
+}
+
+func TestInteractiveCreateSubtask(t *testing.T) {
+	model, taskFile, parent := newSubtaskTestModel(t)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'C'}})
+	require.True(t, model.inputMode)
+	require.Equal(t, parent.ID, model.inputParentID)
+	require.Contains(t, model.View(), `New subtask of "Parent task"`)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Child 3")})
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 4)
+	require.Equal(t, "Child 3", tasks[3].Title)
+	require.Equal(t, parent.ID, tasks[3].ParentID)
+}
+
+func TestInteractiveCompleteParentOffersSubtasks(t *testing.T) {
+	for _, answer := range []string{"y", "n"} {
+		t.Run(answer, func(t *testing.T) {
+			model, taskFile, parent := newSubtaskTestModel(t)
+
+			model.Update(tea.KeyMsg{Type: tea.KeySpace})
+			require.Equal(t, parent.ID, model.confirmComplete)
+			require.True(t, strings.Contains(model.View(), "Also complete 2 open subtasks? (y/n)"))
+
+			model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(answer)})
+			require.Empty(t, model.confirmComplete)
+
+			tasks, err := taskFile.LoadTasks()
+			require.NoError(t, err)
+			for _, task := range tasks {
+				if task.ID == parent.ID {
+					require.Equal(t, StatusDONE, task.Status)
+				} else if answer == "y" {
+					require.Equal(t, StatusDONE, task.Status)
+				} else {
+					require.Equal(t, StatusTODO, task.Status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// timerTickMsg refreshes the running timers in the header
+type timerTickMsg struct{}
+
+func timerTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return timerTickMsg{}
+	})
+}
+
+// scheduleTimerTick returns the next tick while some task has a running timer and no tick is scheduled yet
+func (m *InteractiveTaskList) scheduleTimerTick() tea.Cmd {
+	if m.ticking || !slices.ContainsFunc(m.allTasks, func(t Task) bool { return t.RunningTimer() != nil }) {
+		return nil
+	}
+	m.ticking = true
+	return timerTick()
+}
+
+// renderTimers shows the running timers of DOING tasks
+func (m *InteractiveTaskList) renderTimers() string {
+	var s strings.Builder
+	for _, task := range m.allTasks {
+		entry := task.RunningTimer()
+		if entry == nil {
+			continue
+		}
+		elapsed := time.Since(entry.Start).Truncate(time.Second)
+		s.WriteString(fmt.Sprintf("\x1b[33m⏱ %d:%02d:%02d\x1b[0m %s",
+			int(elapsed.Hours()), int(elapsed.Minutes())%60, int(elapsed.Seconds())%60, task.Title))
+		if total := task.TotalTrackedTime(); total-elapsed >= time.Minute {
+			s.WriteString(fmt.Sprintf(" \x1b[90m(total %s)\x1b[0m", FormatDuration(total)))
+		}
+		s.WriteString("\n")
+	}
+	return s.String()
+}
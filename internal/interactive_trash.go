@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// trashPageSize is how many deleted tasks the trash view shows at once
+const trashPageSize = 15
+
+// openTrash loads the deleted tasks and shows the trash view
+func (m *InteractiveTaskList) openTrash() {
+	trash, err := m.taskFile.LoadTrash()
+	if err != nil {
+		m.err = fmt.Errorf("failed to load trash: %w", err)
+		return
+	}
+	m.trash = trash
+	m.trashCursor = 0
+	m.trashMode = true
+}
+
+// updateTrashMode handles keys in the trash view
+func (m *InteractiveTaskList) updateTrashMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "T":
+		m.trashMode = false
+	case "up", "k":
+		if m.trashCursor > 0 {
+			m.trashCursor--
+		}
+	case "down", "j":
+		if m.trashCursor < len(m.trash)-1 {
+			m.trashCursor++
+		}
+	case "enter", "r":
+		if m.trashCursor < len(m.trash) {
+			m.restoreFromTrash(m.trash[m.trashCursor])
+		}
+	}
+	return m, nil
+}
+
+func (m *InteractiveTaskList) restoreFromTrash(task Task) {
+	if err := m.recordChange("restore task", func() error {
+		_, err := m.taskFile.RestoreFromTrash([]string{task.ID})
+		return err
+	}); err != nil {
+		m.err = fmt.Errorf("failed to restore task: %w", err)
+		return
+	}
+	m.message = "Restored " + task.Title
+
+	if err := m.ReloadTasks(); err != nil {
+		m.err = fmt.Errorf("failed to reload tasks: %w", err)
+		return
+	}
+
+	// Stay in the trash view, and put the list cursor on the restored task
+	m.openTrash()
+	for i, t := range m.tasks {
+		if t.ID == task.ID {
+			m.cursor = i
+			break
+		}
+	}
+}
+
+func (m *InteractiveTaskList) renderTrash(s *strings.Builder) {
+	s.WriteString("\n\n🗑️  Trash:\n\n")
+
+	if len(m.trash) == 0 {
+		s.WriteString("  \x1b[90m(no deleted tasks)\x1b[0m\n")
+	}
+
+	start := max(0, m.trashCursor-trashPageSize/2)
+	end := min(len(m.trash), start+trashPageSize)
+	start = max(0, end-trashPageSize)
+	for i := start; i < end; i++ {
+		task := m.trash[i]
+		cursor := "  "
+		if i == m.trashCursor {
+			cursor = "> "
+		}
+		s.WriteString(fmt.Sprintf("%s\x1b[90m%s\x1b[0m %-7s %s\n",
+			cursor, task.DeletionTime().Format("2006-01-02 15:04"), task.DisplayStatus(), task.Title))
+	}
+	if len(m.trash) > trashPageSize {
+		s.WriteString(fmt.Sprintf("  \x1b[90m(%d/%d)\x1b[0m\n", m.trashCursor+1, len(m.trash)))
+	}
+
+	s.WriteString("\n↑/k: up • ↓/j: down • Enter/r: restore • Esc/q: close")
+	if m.message != "" {
+		s.WriteString("\n\n" + m.message)
+	}
+}
@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxUndoHistory limits how many operations can be undone
+const maxUndoHistory = 100
+
+// historyEntry is an operation of the interactive mode which can be undone
+type historyEntry struct {
+	description string
+	changes     []TaskChange
+}
+
+// recordChange runs op, which modifies the task file, and remembers its changes for undo.
+// Only what op saves is recorded, not changes other processes make meanwhile.
+func (m *InteractiveTaskList) recordChange(description string, op func() error) error {
+	changes, opErr := m.taskFile.CollectChanges(op)
+	if len(changes) > 0 {
+		m.undoStack = append(m.undoStack, historyEntry{description: description, changes: changes})
+		if len(m.undoStack) > maxUndoHistory {
+			m.undoStack = m.undoStack[len(m.undoStack)-maxUndoHistory:]
+		}
+		m.redoStack = nil
+	}
+
+	return opErr
+}
+
+// undo reverts the last recorded operation
+func (m *InteractiveTaskList) undo() {
+	m.replayHistory(&m.undoStack, &m.redoStack, "undo", "Undid")
+}
+
+// redo applies the last undone operation again
+func (m *InteractiveTaskList) redo() {
+	m.replayHistory(&m.redoStack, &m.undoStack, "redo", "Redid")
+}
+
+// replayHistory reverts the last entry of from and pushes the reverting changes to to.
+// If a task has been modified by another process in the meantime, the entry is kept.
+func (m *InteractiveTaskList) replayHistory(from, to *[]historyEntry, action, done string) {
+	if len(*from) == 0 {
+		m.message = fmt.Sprintf("Nothing to %s", action)
+		return
+	}
+	entry := (*from)[len(*from)-1]
+
+	inverted := InvertChanges(entry.changes)
+	applied, err := m.taskFile.ApplyTaskChanges(inverted)
+	if err != nil {
+		m.err = fmt.Errorf("failed to %s %s: %w", action, entry.description, err)
+		return
+	}
+
+	*from = (*from)[:len(*from)-1]
+	*to = append(*to, historyEntry{description: entry.description, changes: applied})
+
+	// Restored tasks got a new Updated time, which older entries have to expect now
+	for i, c := range inverted {
+		if c.After != nil {
+			m.retimeHistory(c.After.ID, c.After.Updated, applied[i].After.Updated)
+		}
+	}
+	m.message = fmt.Sprintf("%s %s", done, entry.description)
+
+	if err := m.ReloadTasks(); err != nil {
+		m.err = fmt.Errorf("failed to reload tasks: %w", err)
+		return
+	}
+
+	// Move the cursor to the changed task
+	for i, task := range m.tasks {
+		if task.ID == applied[0].taskID() {
+			m.cursor = i
+			break
+		}
+	}
+}
+
+// retimeHistory replaces the Updated time of a task state in the undo and redo history
+func (m *InteractiveTaskList) retimeHistory(taskID string, from, to time.Time) {
+	retime := func(task *Task) *Task {
+		if task == nil || task.ID != taskID || !task.Updated.Equal(from) {
+			return task
+		}
+		retimed := *task
+		retimed.Updated = to
+		return &retimed
+	}
+
+	for _, stack := range [][]historyEntry{m.undoStack, m.redoStack} {
+		for _, entry := range stack {
+			for i, c := range entry.changes {
+				entry.changes[i] = TaskChange{Before: retime(c.Before), After: retime(c.After)}
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+)
+
+func pressKey(t *testing.T, model *InteractiveTaskList, key string) {
+	t.Helper()
+	var msg tea.KeyMsg
+	switch key {
+	case "ctrl+r":
+		msg = tea.KeyMsg{Type: tea.KeyCtrlR}
+	case " ":
+		msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	model.Update(msg)
+}
+
+func taskExists(t *testing.T, taskFile *TaskFile, id string) bool {
+	t.Helper()
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	for _, task := range tasks {
+		if task.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestInteractiveUndoRedo(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	task := NewTask("Undo me")
+	require.NoError(t, taskFile.AddTask(task))
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+
+	pressKey(t, model, "s")
+	pressKey(t, model, "+")
+	require.Equal(t, StatusDOING, loadTaskByID(t, taskFile, task.ID).Status)
+	require.Equal(t, "C", loadTaskByID(t, taskFile, task.ID).Priority)
+
+	// Undo both changes, newest first
+	pressKey(t, model, "u")
+	require.Equal(t, "", loadTaskByID(t, taskFile, task.ID).Priority)
+	require.Contains(t, model.renderFooter(), "Undid raise priority")
+	pressKey(t, model, "u")
+	require.Equal(t, StatusTODO, loadTaskByID(t, taskFile, task.ID).Status)
+
+	pressKey(t, model, "u")
+	require.Contains(t, model.renderFooter(), "Nothing to undo")
+
+	// Redo them again
+	pressKey(t, model, "ctrl+r")
+	pressKey(t, model, "ctrl+r")
+	require.Equal(t, StatusDOING, loadTaskByID(t, taskFile, task.ID).Status)
+	require.Equal(t, "C", loadTaskByID(t, taskFile, task.ID).Priority)
+	require.NoError(t, model.err)
+
+	// A new change clears the redo history
+	pressKey(t, model, "u")
+	pressKey(t, model, "-")
+	pressKey(t, model, "ctrl+r")
+	require.Contains(t, model.renderFooter(), "Nothing to redo")
+}
+
+func TestInteractiveUndoDelete(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	task := NewTask("Deleted by mistake")
+	require.NoError(t, taskFile.AddTask(task))
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+
+	pressKey(t, model, "d")
+	pressKey(t, model, "y")
+	require.False(t, taskExists(t, taskFile, task.ID))
+
+	pressKey(t, model, "u")
+	require.True(t, taskExists(t, taskFile, task.ID), "task should be restored with the same ID")
+	restored := loadTaskByID(t, taskFile, task.ID)
+	require.Equal(t, "Deleted by mistake", restored.Title)
+	require.Equal(t, task.Created.Unix(), restored.Created.Unix())
+	require.Len(t, model.tasks, 1)
+
+	trash, err := taskFile.LoadTrash()
+	require.NoError(t, err)
+	require.Empty(t, trash, "the restored task should leave the trash")
+
+	pressKey(t, model, "ctrl+r")
+	require.False(t, taskExists(t, taskFile, task.ID))
+}
+
+func TestInteractiveUndoRespectsConflicts(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	task := NewTask("Shared task")
+	require.NoError(t, taskFile.AddTask(task))
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+
+	pressKey(t, model, "+")
+
+	// Another process changes the task afterwards
+	current := loadTaskByID(t, taskFile, task.ID)
+	require.NoError(t, taskFile.UpdateTaskWithConflictCheck(task.ID, current.Updated, func(t *Task) {
+		t.Title = "Changed elsewhere"
+	}))
+
+	pressKey(t, model, "u")
+	require.Error(t, model.err)
+	require.ErrorIs(t, model.err, ErrTaskModified)
+	require.Equal(t, "Changed elsewhere", loadTaskByID(t, taskFile, task.ID).Title)
+	require.Len(t, model.undoStack, 1, "the failed undo should be kept")
+}
+
+func TestInteractiveUndoKeepsChangesOfOtherProcesses(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	mine := NewTask("Mine")
+	theirs := NewTask("Theirs")
+	require.NoError(t, taskFile.AddTasks([]Task{*mine, *theirs}))
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+
+	// Another process saves while the operation runs
+	other := NewTaskFileWithPath(taskFile.Path)
+	require.NoError(t, model.recordChange("raise priority", func() error {
+		if err := other.UpdateTaskWithConflictCheck(theirs.ID, theirs.Updated, func(t *Task) {
+			t.Title = "Theirs, renamed elsewhere"
+		}); err != nil {
+			return err
+		}
+		return taskFile.UpdateTaskWithConflictCheck(mine.ID, mine.Updated, func(t *Task) {
+			t.IncreasePriority()
+		})
+	}))
+	require.Len(t, model.undoStack[0].changes, 1)
+
+	pressKey(t, model, "u")
+	require.Equal(t, "", loadTaskByID(t, taskFile, mine.ID).Priority)
+	require.Equal(t, "Theirs, renamed elsewhere", loadTaskByID(t, taskFile, theirs.ID).Title)
+}
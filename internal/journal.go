@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Fields of journal entries for tasks which appear in or disappear from the task file.
+// The title of the task is the new or old value.
+const (
+	JournalCreated  = "created"
+	JournalDeleted  = "deleted"
+	JournalArchived = "archived"
+	JournalRestored = "restored"
+)
+
+// JournalEntry is a change of one field of a task, as recorded in the change journal
+type JournalEntry struct {
+	TaskID string          `json:"task_id"`
+	Field  string          `json:"field"` // JSON name of the field, or one of JournalCreated etc.
+	Old    json.RawMessage `json:"old,omitempty"`
+	New    json.RawMessage `json:"new,omitempty"`
+	Time   time.Time       `json:"time"`
+	Actor  string          `json:"actor,omitempty"`
+}
+
+// JournalFilePath returns the path of the change journal, next to the task file
+func (tf *TaskFile) JournalFilePath() string {
+	dir := filepath.Dir(tf.Path)
+	base := filepath.Base(tf.Path)
+	ext := filepath.Ext(base)
+	return filepath.Join(dir, base[:len(base)-len(ext)]+".journal"+ext)
+}
+
+// DefaultActor returns the name of the user running taskeru
+func DefaultActor() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
+
+// saveTasksAs saves the tasks and appends their changes since before, the tasks loaded under
+// the lock, to the journal. Tasks which appear in the file are recorded as added, those which
+// disappear as removed.
+func (tf *TaskFile) saveTasksAs(before, tasks []Task, added, removed string) error {
+	if err := writeTaskLines(tf.Path, ".taskeru-*.tmp", tasks); err != nil {
+		return err
+	}
+
+	changes := DiffTasks(before, tasks)
+	tf.collectChanges(changes)
+	tf.journal(changes, added, removed)
+	return nil
+}
+
+// journal appends changes which have been saved to the journal.
+// The tasks are saved already, so a journal which can't be written only loses history.
+func (tf *TaskFile) journal(changes []TaskChange, added, removed string) {
+	actor := tf.Actor
+	if actor == "" {
+		actor = DefaultActor()
+	}
+	if err := tf.appendJournal(journalEntries(changes, added, removed, actor, time.Now())); err != nil {
+		slog.Error("Failed to write change journal",
+			slog.String("path", tf.JournalFilePath()),
+			slog.Any("error", err))
+	}
+}
+
+// journalEntries turns changes into journal entries, one per changed field
+func journalEntries(changes []TaskChange, added, removed, actor string, now time.Time) []JournalEntry {
+	var entries []JournalEntry
+	for _, c := range changes {
+		entry := JournalEntry{TaskID: c.taskID(), Time: now, Actor: actor}
+		switch {
+		case c.Before == nil:
+			entry.Field = added
+			var err error
+			if entry.New, err = json.Marshal(c.After.Title); err != nil {
+				continue
+			}
+			entries = append(entries, entry)
+		case c.After == nil:
+			entry.Field = removed
+			var err error
+			if entry.Old, err = json.Marshal(c.Before.Title); err != nil {
+				continue
+			}
+			entries = append(entries, entry)
+		default:
+			before := reflect.ValueOf(*c.Before)
+			after := reflect.ValueOf(*c.After)
+			for i := 0; i < before.NumField(); i++ {
+				entry.Field = taskFieldName(before.Type().Field(i))
+				if entry.Field == "" || entry.Field == "updated" {
+					continue
+				}
+				var err error
+				if entry.Old, err = fieldJSON(before.Field(i)); err != nil {
+					continue
+				}
+				if entry.New, err = fieldJSON(after.Field(i)); err != nil || bytes.Equal(entry.Old, entry.New) {
+					continue
+				}
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// appendJournal appends entries to the journal, one JSON object per line
+func (tf *TaskFile) appendJournal(entries []JournalEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var b bytes.Buffer
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+
+	file, err := os.OpenFile(tf.JournalFilePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	// A single write keeps the lines of one save together
+	if _, err := file.Write(b.Bytes()); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadJournal returns the journal entries of the task with the given ID, oldest first.
+// An empty ID returns the entries of all tasks.
+func (tf *TaskFile) LoadJournal(taskID string) ([]JournalEntry, error) {
+	file, err := os.Open(tf.JournalFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(file)
+	// Entries of long notes hold the whole note twice
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			slog.Error("Failed to unmarshal journal entry",
+				slog.String("line_content", line),
+				slog.Any("error", err))
+			continue
+		}
+		if taskID == "" || entry.TaskID == taskID {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read change journal: %w", err)
+	}
+	return entries, nil
+}
+
+// FormatJournalValue returns an old or new value of a journal entry for display
+func FormatJournalValue(value json.RawMessage) string {
+	if len(value) == 0 {
+		return "(none)"
+	}
+
+	var decoded any
+	if err := json.Unmarshal(value, &decoded); err != nil {
+		return string(value)
+	}
+	switch v := decoded.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t.Local().Format("2006-01-02 15:04")
+		}
+		return v
+	case []any:
+		var items []string
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				// e.g. the time log
+				return fmt.Sprintf("%d entries", len(v))
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ", ")
+	}
+	return string(value)
+}
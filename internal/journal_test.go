@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalRecordsEveryChange(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	taskFile.Actor = "alice (cli)"
+	task := NewTask("Write report")
+	other := NewTask("Other task")
+	require.NoError(t, taskFile.AddTasks([]Task{*task, *other}))
+
+	require.NoError(t, taskFile.UpdateTaskWithConflictCheck(task.ID, task.Updated, func(t *Task) {
+		t.SetPriority("A")
+		t.SetStatus(StatusWAITING)
+	}))
+	taskFile.Actor = "bob (web)"
+	require.NoError(t, taskFile.DeleteTask(task.ID))
+	_, err := taskFile.RestoreFromTrash([]string{task.ID})
+	require.NoError(t, err)
+
+	entries, err := taskFile.LoadJournal(task.ID)
+	require.NoError(t, err)
+	type change struct{ field, old, new, actor string }
+	var changes []change
+	for _, entry := range entries {
+		require.Equal(t, task.ID, entry.TaskID)
+		require.False(t, entry.Time.IsZero())
+		changes = append(changes, change{entry.Field, FormatJournalValue(entry.Old), FormatJournalValue(entry.New), entry.Actor})
+	}
+	require.Equal(t, []change{
+		{"created", "(none)", "Write report", "alice (cli)"},
+		{"priority", "(none)", "A", "alice (cli)"},
+		{"status", "TODO", "WAITING", "alice (cli)"},
+		{"deleted", "Write report", "(none)", "bob (web)"},
+		{"restored", "(none)", "Write report", "bob (web)"},
+	}, changes)
+
+	// Without an ID, the entries of all tasks are returned
+	all, err := taskFile.LoadJournal("")
+	require.NoError(t, err)
+	require.Len(t, all, len(entries)+1)
+}
+
+func TestJournalRecordsArchivedTasks(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	task := NewTask("Old task")
+	task.SetStatus(StatusDONE)
+	require.NoError(t, taskFile.AddTask(task))
+
+	archived, err := taskFile.ArchiveTasks(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, archived)
+
+	entries, err := taskFile.LoadJournal(task.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, JournalArchived, entries[1].Field)
+	require.Equal(t, DefaultActor(), entries[1].Actor)
+}
+
+func TestFormatJournalValue(t *testing.T) {
+	due := time.Date(2025, 3, 21, 17, 0, 0, 0, time.Local)
+	dueJSON, err := json.Marshal(due)
+	require.NoError(t, err)
+
+	require.Equal(t, "(none)", FormatJournalValue(nil))
+	require.Equal(t, "2025-03-21 17:00", FormatJournalValue(dueJSON))
+	require.Equal(t, "work, docs", FormatJournalValue(json.RawMessage(`["work","docs"]`)))
+	require.Equal(t, "2 entries", FormatJournalValue(json.RawMessage(`[{"start":"x"},{"start":"y"}]`)))
+	require.Equal(t, "3.5", FormatJournalValue(json.RawMessage(`3.5`)))
+}
@@ -0,0 +1,333 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a parsed filter expression such as
+//
+//	status:DOING prio:<=B +work due:<7d -has:note "free text"
+//
+// All terms must match. A leading "-" negates a term.
+type Query struct {
+	terms []queryTerm
+}
+
+type queryTerm struct {
+	negate bool
+	match  func(task *Task, now time.Time) bool
+}
+
+var relativeDayRegex = regexp.MustCompile(`^([+-]?\d+)([dw])$`)
+
+// ParseQuery parses a filter expression. Supported terms:
+//
+//	+project, project:name       Task belongs to the project
+//	status:DOING,WAITING         Status (also: open, closed)
+//	prio:A, prio:<=B, prio:none  Priority (A is the highest)
+//	due:<7d, sched:today         Deadline / scheduled date (also: created, updated, completed)
+//	has:note                     Field is set (note, due, sched, prio, project, repeat, parent, blockers)
+//	id:0198a1b2                  ID prefix
+//	text, "free text"            Substring of title, note or projects (case insensitive)
+//
+// Terms with an unknown key are matched as free text.
+func ParseQuery(input string) (*Query, error) {
+	tokens, err := tokenizeQuery(input)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &Query{}
+	for _, token := range tokens {
+		term := queryTerm{}
+		if !token.quoted && len(token.text) > 1 && strings.HasPrefix(token.text, "-") {
+			term.negate = true
+			token.text = token.text[1:]
+		}
+
+		if token.quoted {
+			term.match = matchText(token.text)
+		} else {
+			term.match, err = parseQueryTerm(token.text)
+			if err != nil {
+				return nil, err
+			}
+		}
+		query.terms = append(query.terms, term)
+	}
+	return query, nil
+}
+
+// IsEmpty reports whether the query has no terms, i.e. matches every task
+func (q *Query) IsEmpty() bool {
+	return q == nil || len(q.terms) == 0
+}
+
+// Match reports whether the task matches all terms of the query
+func (q *Query) Match(task *Task) bool {
+	if q.IsEmpty() {
+		return true
+	}
+
+	now := time.Now()
+	for _, term := range q.terms {
+		if term.match(task, now) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterTasksByQuery returns tasks matching the query
+func FilterTasksByQuery(tasks []Task, query *Query) []Task {
+	if query.IsEmpty() {
+		return tasks
+	}
+
+	var filtered []Task
+	for i := range tasks {
+		if query.Match(&tasks[i]) {
+			filtered = append(filtered, tasks[i])
+		}
+	}
+	return filtered
+}
+
+type queryToken struct {
+	text   string
+	quoted bool
+}
+
+// tokenizeQuery splits the input on whitespace, keeping "quoted strings" together
+func tokenizeQuery(input string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		switch {
+		case runes[i] == ' ' || runes[i] == '\t':
+			i++
+		case runes[i] == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated quote in query")
+			}
+			if end > i+1 {
+				tokens = append(tokens, queryToken{text: string(runes[i+1 : end]), quoted: true})
+			}
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && runes[end] != ' ' && runes[end] != '\t' {
+				end++
+			}
+			tokens = append(tokens, queryToken{text: string(runes[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+func parseQueryTerm(text string) (func(*Task, time.Time) bool, error) {
+	if strings.HasPrefix(text, "+") && len(text) > 1 {
+		return matchProject(text[1:]), nil
+	}
+
+	key, value, found := strings.Cut(text, ":")
+	if !found || value == "" {
+		return matchText(text), nil
+	}
+
+	switch strings.ToLower(key) {
+	case "project":
+		return matchProject(value), nil
+	case "status":
+		return parseStatusTerm(value)
+	case "prio", "priority":
+		return parsePriorityTerm(value)
+	case "due":
+		return parseDateTerm(value, func(t *Task) *time.Time { return t.DueDate })
+	case "sched", "scheduled":
+		return parseDateTerm(value, func(t *Task) *time.Time { return t.ScheduledDate })
+	case "completed":
+		return parseDateTerm(value, func(t *Task) *time.Time { return t.CompletedAt })
+	case "created":
+		return parseDateTerm(value, func(t *Task) *time.Time { return &t.Created })
+	case "updated":
+		return parseDateTerm(value, func(t *Task) *time.Time { return &t.Updated })
+	case "has":
+		return parseHasTerm(value)
+	case "id":
+		prefix := strings.ToLower(value)
+		return func(t *Task, _ time.Time) bool {
+			return strings.HasPrefix(strings.ToLower(t.ID), prefix)
+		}, nil
+	default:
+		return matchText(text), nil
+	}
+}
+
+func matchText(text string) func(*Task, time.Time) bool {
+	text = strings.ToLower(text)
+	return func(t *Task, _ time.Time) bool {
+		if strings.Contains(strings.ToLower(t.Title), text) || strings.Contains(strings.ToLower(t.Note), text) {
+			return true
+		}
+		for _, project := range t.Projects {
+			if strings.Contains(strings.ToLower(project), text) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func matchProject(project string) func(*Task, time.Time) bool {
+	return func(t *Task, _ time.Time) bool {
+		for _, p := range t.Projects {
+			if p == project {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func parseStatusTerm(value string) (func(*Task, time.Time) bool, error) {
+	var statuses []string
+	for _, status := range strings.Split(value, ",") {
+		status = strings.ToUpper(status)
+		switch status {
+		case StatusTODO, StatusDOING, StatusWAITING, StatusDONE, StatusWONTDO, "OPEN", "CLOSED":
+			statuses = append(statuses, status)
+		default:
+			return nil, fmt.Errorf("invalid status in query: %s", status)
+		}
+	}
+
+	return func(t *Task, _ time.Time) bool {
+		for _, status := range statuses {
+			switch {
+			case status == "OPEN" && !t.IsCompleted(),
+				status == "CLOSED" && t.IsCompleted(),
+				status == t.Status:
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// splitComparison splits "<=B" into "<=" and "B". No operator means "=".
+func splitComparison(value string) (string, string) {
+	for _, op := range []string{"<=", ">=", "<", ">", "="} {
+		if strings.HasPrefix(value, op) {
+			return op, value[len(op):]
+		}
+	}
+	return "=", value
+}
+
+func compare(op string, cmp int) bool {
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// parsePriorityTerm compares priorities alphabetically, so prio:<=B matches A and B.
+// Tasks without priority only match prio:none.
+func parsePriorityTerm(value string) (func(*Task, time.Time) bool, error) {
+	op, priority := splitComparison(strings.ToUpper(value))
+	if priority == "NONE" && op == "=" {
+		return func(t *Task, _ time.Time) bool { return t.Priority == "" }, nil
+	}
+	if len(priority) != 1 || priority[0] < 'A' || priority[0] > 'Z' {
+		return nil, fmt.Errorf("invalid priority in query: %s", value)
+	}
+
+	return func(t *Task, _ time.Time) bool {
+		if t.Priority == "" {
+			return false
+		}
+		return compare(op, strings.Compare(t.Priority, priority))
+	}, nil
+}
+
+// parseDateTerm compares dates by day. The value is a relative day count (7d, -2w)
+// or anything ParseNaturalDate accepts (today, friday, 2025-01-31).
+func parseDateTerm(value string, field func(*Task) *time.Time) (func(*Task, time.Time) bool, error) {
+	op, dateStr := splitComparison(value)
+	if strings.EqualFold(dateStr, "none") && op == "=" {
+		return func(t *Task, _ time.Time) bool { return field(t) == nil }, nil
+	}
+
+	var resolve func(now time.Time) time.Time
+	if m := relativeDayRegex.FindStringSubmatch(dateStr); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if m[2] == "w" {
+			n *= 7
+		}
+		resolve = func(now time.Time) time.Time { return now.AddDate(0, 0, n) }
+	} else {
+		date, _ := ParseNaturalDate(dateStr)
+		if date == nil {
+			return nil, fmt.Errorf("invalid date in query: %s", value)
+		}
+		resolve = func(time.Time) time.Time { return *date }
+	}
+
+	return func(t *Task, now time.Time) bool {
+		date := field(t)
+		if date == nil {
+			return false
+		}
+		return compare(op, compareDays(*date, resolve(now)))
+	}, nil
+}
+
+// compareDays compares the local calendar days of a and b
+func compareDays(a, b time.Time) int {
+	a, b = a.Local(), b.Local()
+	dayA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.Local)
+	dayB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.Local)
+	return dayA.Compare(dayB)
+}
+
+func parseHasTerm(value string) (func(*Task, time.Time) bool, error) {
+	switch strings.ToLower(value) {
+	case "note":
+		return func(t *Task, _ time.Time) bool { return strings.TrimSpace(t.Note) != "" }, nil
+	case "due":
+		return func(t *Task, _ time.Time) bool { return t.DueDate != nil }, nil
+	case "sched", "scheduled":
+		return func(t *Task, _ time.Time) bool { return t.ScheduledDate != nil }, nil
+	case "prio", "priority":
+		return func(t *Task, _ time.Time) bool { return t.Priority != "" }, nil
+	case "project":
+		return func(t *Task, _ time.Time) bool { return len(t.Projects) > 0 }, nil
+	case "repeat":
+		return func(t *Task, _ time.Time) bool { return t.Recurrence != "" }, nil
+	case "parent":
+		return func(t *Task, _ time.Time) bool { return t.ParentID != "" }, nil
+	case "blockers":
+		return func(t *Task, _ time.Time) bool { return len(t.BlockedBy) > 0 }, nil
+	default:
+		return nil, fmt.Errorf("unknown field in query: has:%s", value)
+	}
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQueryMatch(t *testing.T) {
+	now := time.Now()
+	in3Days := now.AddDate(0, 0, 3)
+	in10Days := now.AddDate(0, 0, 10)
+
+	report := Task{ID: "0198a1", Title: "Write report", Status: StatusDOING, Priority: "A",
+		Projects: []string{"work"}, DueDate: &in3Days, Note: "Quarterly numbers"}
+	review := Task{ID: "0198b2", Title: "Code review", Status: StatusTODO, Priority: "C",
+		Projects: []string{"work", "oss"}, DueDate: &in10Days}
+	milk := Task{ID: "0198c3", Title: "Buy milk", Status: StatusDONE, Projects: []string{"home"}}
+	tasks := []Task{report, review, milk}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "", expected: []string{"Write report", "Code review", "Buy milk"}},
+		{query: "status:DOING", expected: []string{"Write report"}},
+		{query: "status:todo,done", expected: []string{"Code review", "Buy milk"}},
+		{query: "status:open", expected: []string{"Write report", "Code review"}},
+		{query: "prio:<=B", expected: []string{"Write report"}},
+		{query: "prio:>A", expected: []string{"Code review"}},
+		{query: "prio:none", expected: []string{"Buy milk"}},
+		{query: "+work", expected: []string{"Write report", "Code review"}},
+		{query: "project:oss", expected: []string{"Code review"}},
+		{query: "-+work", expected: []string{"Buy milk"}},
+		{query: "due:<7d", expected: []string{"Write report"}},
+		{query: "due:>=7d", expected: []string{"Code review"}},
+		{query: "due:none", expected: []string{"Buy milk"}},
+		{query: "has:note", expected: []string{"Write report"}},
+		{query: "+work -has:note", expected: []string{"Code review"}},
+		{query: "id:0198b", expected: []string{"Code review"}},
+		{query: "milk", expected: []string{"Buy milk"}},
+		{query: "quarterly", expected: []string{"Write report"}},
+		{query: `"code review"`, expected: []string{"Code review"}},
+		{query: `"-has:note"`, expected: nil},
+		{query: "status:DOING prio:<=B +work due:<7d has:note", expected: []string{"Write report"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			var titles []string
+			for _, task := range FilterTasksByQuery(tasks, query) {
+				titles = append(titles, task.Title)
+			}
+			require.Equal(t, tt.expected, titles)
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, input := range []string{
+		"status:SOMEDAY",
+		"prio:<=1",
+		"due:<someday",
+		"has:everything",
+		`"unterminated`,
+	} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseQuery(input)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseQueryUnknownKeyIsText(t *testing.T) {
+	query, err := ParseQuery("10:30")
+	require.NoError(t, err)
+	require.True(t, query.Match(&Task{Title: "Meeting at 10:30"}))
+	require.False(t, query.Match(&Task{Title: "Meeting at 11:00"}))
+}
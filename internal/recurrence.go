@@ -0,0 +1,225 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence describes how a repeating task comes back after it is completed.
+// It is parsed from the rule stored in Task.Recurrence, e.g.:
+//   - daily, weekly, monthly, yearly
+//   - weekdays (Monday to Friday)
+//   - mon,wed,fri (comma separated list of weekdays)
+//   - 3d, 2w, 6m, 1y (every N days/weeks/months/years)
+//   - +3d, +2w (N days/weeks after completion)
+type Recurrence struct {
+	Days           int
+	Months         int
+	Weekdays       []time.Weekday
+	FromCompletion bool
+}
+
+var recurrenceIntervalRegex = regexp.MustCompile(`^(\+?)(\d+)([dwmy])$`)
+
+// ParseRecurrence parses a recurrence rule
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	rule = strings.ToLower(strings.TrimSpace(rule))
+
+	switch rule {
+	case "":
+		return nil, fmt.Errorf("empty recurrence rule")
+	case "daily":
+		return &Recurrence{Days: 1}, nil
+	case "weekly":
+		return &Recurrence{Days: 7}, nil
+	case "monthly":
+		return &Recurrence{Months: 1}, nil
+	case "yearly":
+		return &Recurrence{Months: 12}, nil
+	case "weekdays":
+		return &Recurrence{Weekdays: []time.Weekday{
+			time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday,
+		}}, nil
+	}
+
+	if match := recurrenceIntervalRegex.FindStringSubmatch(rule); match != nil {
+		n, err := strconv.Atoi(match[2])
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid recurrence interval: %s", rule)
+		}
+
+		r := &Recurrence{FromCompletion: match[1] == "+"}
+		switch match[3] {
+		case "d":
+			r.Days = n
+		case "w":
+			r.Days = n * 7
+		case "m":
+			r.Months = n
+		case "y":
+			r.Months = n * 12
+		}
+		return r, nil
+	}
+
+	// Comma separated list of weekdays
+	var weekdays []time.Weekday
+	for _, name := range strings.Split(rule, ",") {
+		weekday, ok := parseWeekday(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("invalid recurrence rule: %s", rule)
+		}
+		weekdays = append(weekdays, weekday)
+	}
+	return &Recurrence{Weekdays: weekdays}, nil
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	switch name {
+	case "sunday", "sun":
+		return time.Sunday, true
+	case "monday", "mon":
+		return time.Monday, true
+	case "tuesday", "tue":
+		return time.Tuesday, true
+	case "wednesday", "wed":
+		return time.Wednesday, true
+	case "thursday", "thu":
+		return time.Thursday, true
+	case "friday", "fri":
+		return time.Friday, true
+	case "saturday", "sat":
+		return time.Saturday, true
+	}
+	return 0, false
+}
+
+// Next returns the next occurrence date for a task anchored at anchor and completed at completed.
+// The result is always on a day after the completion day, so overdue tasks do not come back in the past.
+func (r *Recurrence) Next(anchor, completed time.Time) time.Time {
+	if r.FromCompletion {
+		base := time.Date(completed.Year(), completed.Month(), completed.Day(),
+			anchor.Hour(), anchor.Minute(), anchor.Second(), 0, anchor.Location())
+		return addMonthsClamped(base, r.Months).AddDate(0, 0, r.Days)
+	}
+
+	if len(r.Weekdays) > 0 {
+		next := anchor.AddDate(0, 0, 1)
+		for !r.hasWeekday(next.Weekday()) || !isAfterDay(next, completed) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	}
+
+	// Always step from the original anchor to avoid drifting on month ends
+	next := addMonthsClamped(anchor, r.Months).AddDate(0, 0, r.Days)
+	for i := 2; !isAfterDay(next, completed); i++ {
+		next = addMonthsClamped(anchor, r.Months*i).AddDate(0, 0, r.Days*i)
+	}
+	return next
+}
+
+// addMonthsClamped adds months like AddDate, but stays on the last day of a shorter month:
+// Jan 31 plus a month is Feb 28 (or 29), not Mar 3
+func addMonthsClamped(t time.Time, months int) time.Time {
+	if months == 0 {
+		return t
+	}
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
+
+func (r *Recurrence) hasWeekday(weekday time.Weekday) bool {
+	for _, w := range r.Weekdays {
+		if w == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// isAfterDay reports whether t falls on a calendar day after ref
+func isAfterDay(t, ref time.Time) bool {
+	ref = ref.In(t.Location())
+	refDay := time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, t.Location())
+	return !t.Before(refDay.AddDate(0, 0, 1))
+}
+
+// daysBetween returns the number of calendar days from a to b
+func daysBetween(a, b time.Time) int {
+	aDay := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	bDay := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(bDay.Sub(aDay).Hours() / 24)
+}
+
+// NextOccurrence creates the next instance of a recurring task.
+// Due and scheduled dates are shifted by the same number of days, so their distance is kept.
+// It returns nil if the task does not repeat.
+func (t *Task) NextOccurrence() *Task {
+	if t.Recurrence == "" {
+		return nil
+	}
+	rec, err := ParseRecurrence(t.Recurrence)
+	if err != nil {
+		return nil
+	}
+
+	completed := time.Now()
+	if t.CompletedAt != nil {
+		completed = *t.CompletedAt
+	}
+
+	var anchor time.Time
+	switch {
+	case t.DueDate != nil:
+		anchor = *t.DueDate
+	case t.ScheduledDate != nil:
+		anchor = *t.ScheduledDate
+	default:
+		anchor = time.Date(completed.Year(), completed.Month(), completed.Day(), 0, 0, 0, 0, completed.Location())
+	}
+	shift := daysBetween(anchor, rec.Next(anchor, completed))
+
+	next := NewTask(t.Title)
+	next.Priority = t.Priority
+	next.Note = t.Note
+	next.Recurrence = t.Recurrence
+	next.RecurredFrom = t.ID
+	if len(t.Projects) > 0 {
+		next.Projects = append([]string{}, t.Projects...)
+	}
+
+	if t.DueDate == nil && t.ScheduledDate == nil {
+		// Without any date, hide the task until its next day comes
+		scheduled := anchor.AddDate(0, 0, shift)
+		next.ScheduledDate = &scheduled
+		return next
+	}
+	if t.DueDate != nil {
+		due := t.DueDate.AddDate(0, 0, shift)
+		next.DueDate = &due
+	}
+	if t.ScheduledDate != nil {
+		scheduled := t.ScheduledDate.AddDate(0, 0, shift)
+		next.ScheduledDate = &scheduled
+	}
+	return next
+}
+
+// takeBackOccurrence removes the next occurrence of the reopened tasks[i] and gives its rule back,
+// so that completing it again doesn't repeat it twice. An occurrence changed since is kept.
+func takeBackOccurrence(tasks []Task, i int) []Task {
+	for j, task := range tasks {
+		if task.RecurredFrom != tasks[i].ID || task.IsCompleted() || !task.Updated.Equal(task.Created) {
+			continue
+		}
+		tasks[i].Recurrence = task.Recurrence
+		return slices.Delete(tasks, j, j+1)
+	}
+	return tasks
+}
@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		rule     string
+		expected *Recurrence
+		wantErr  bool
+	}{
+		{rule: "daily", expected: &Recurrence{Days: 1}},
+		{rule: "Weekly", expected: &Recurrence{Days: 7}},
+		{rule: "monthly", expected: &Recurrence{Months: 1}},
+		{rule: "yearly", expected: &Recurrence{Months: 12}},
+		{rule: "3d", expected: &Recurrence{Days: 3}},
+		{rule: "2w", expected: &Recurrence{Days: 14}},
+		{rule: "+3d", expected: &Recurrence{Days: 3, FromCompletion: true}},
+		{rule: "mon,fri", expected: &Recurrence{Weekdays: []time.Weekday{time.Monday, time.Friday}}},
+		{rule: "weekdays", expected: &Recurrence{Weekdays: []time.Weekday{
+			time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday,
+		}}},
+		{rule: "", wantErr: true},
+		{rule: "0d", wantErr: true},
+		{rule: "sometimes", wantErr: true},
+		{rule: "mon,someday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rec, err := ParseRecurrence(tt.rule)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, rec)
+		})
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	// 2025-01-03 is a Friday
+	friday := time.Date(2025, 1, 3, 23, 59, 59, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		rule      string
+		anchor    time.Time
+		completed time.Time
+		expected  time.Time
+	}{
+		{
+			name:      "weekly completed on time",
+			rule:      "weekly",
+			anchor:    friday,
+			completed: friday.Add(-2 * time.Hour),
+			expected:  friday.AddDate(0, 0, 7),
+		},
+		{
+			name:      "daily overdue skips past days",
+			rule:      "daily",
+			anchor:    friday,
+			completed: friday.AddDate(0, 0, 3),
+			expected:  friday.AddDate(0, 0, 4),
+		},
+		{
+			name:      "monthly",
+			rule:      "monthly",
+			anchor:    friday,
+			completed: friday,
+			expected:  time.Date(2025, 2, 3, 23, 59, 59, 0, time.Local),
+		},
+		{
+			name:      "monthly from the 31st ends on the last day of February",
+			rule:      "monthly",
+			anchor:    time.Date(2025, 1, 31, 9, 0, 0, 0, time.Local),
+			completed: time.Date(2025, 1, 31, 9, 0, 0, 0, time.Local),
+			expected:  time.Date(2025, 2, 28, 9, 0, 0, 0, time.Local),
+		},
+		{
+			name:      "monthly from the 31st in a leap year",
+			rule:      "monthly",
+			anchor:    time.Date(2024, 1, 31, 9, 0, 0, 0, time.Local),
+			completed: time.Date(2024, 1, 31, 9, 0, 0, 0, time.Local),
+			expected:  time.Date(2024, 2, 29, 9, 0, 0, 0, time.Local),
+		},
+		{
+			name:      "monthly overdue from the 31st",
+			rule:      "monthly",
+			anchor:    time.Date(2025, 1, 31, 9, 0, 0, 0, time.Local),
+			completed: time.Date(2025, 3, 5, 9, 0, 0, 0, time.Local),
+			expected:  time.Date(2025, 3, 31, 9, 0, 0, 0, time.Local),
+		},
+		{
+			name:      "yearly from Feb 29",
+			rule:      "yearly",
+			anchor:    time.Date(2024, 2, 29, 9, 0, 0, 0, time.Local),
+			completed: time.Date(2024, 2, 29, 9, 0, 0, 0, time.Local),
+			expected:  time.Date(2025, 2, 28, 9, 0, 0, 0, time.Local),
+		},
+		{
+			name:      "after completion on the 31st",
+			rule:      "+1m",
+			anchor:    time.Date(2025, 1, 10, 9, 0, 0, 0, time.Local),
+			completed: time.Date(2025, 1, 31, 18, 0, 0, 0, time.Local),
+			expected:  time.Date(2025, 2, 28, 9, 0, 0, 0, time.Local),
+		},
+		{
+			name:      "weekday list",
+			rule:      "mon,wed",
+			anchor:    friday,
+			completed: friday,
+			expected:  friday.AddDate(0, 0, 3),
+		},
+		{
+			name:      "after completion",
+			rule:      "+3d",
+			anchor:    friday,
+			completed: friday.AddDate(0, 0, 5),
+			expected:  friday.AddDate(0, 0, 8),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := ParseRecurrence(tt.rule)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, rec.Next(tt.anchor, tt.completed))
+		})
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	t.Run("non-recurring task", func(t *testing.T) {
+		task := NewTask("One shot")
+		require.Nil(t, task.NextOccurrence())
+	})
+
+	t.Run("shifts due and scheduled dates together", func(t *testing.T) {
+		due := time.Date(2025, 1, 3, 23, 59, 59, 0, time.Local)
+		scheduled := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+		completed := time.Date(2025, 1, 3, 10, 0, 0, 0, time.Local)
+
+		task := NewTask("Weekly report")
+		task.Recurrence = "weekly"
+		task.DueDate = &due
+		task.ScheduledDate = &scheduled
+		task.Priority = "B"
+		task.Projects = []string{"work"}
+		task.Status = StatusDONE
+		task.CompletedAt = &completed
+
+		next := task.NextOccurrence()
+		require.NotNil(t, next)
+		require.NotEqual(t, task.ID, next.ID)
+		require.Equal(t, "Weekly report", next.Title)
+		require.Equal(t, StatusTODO, next.Status)
+		require.Equal(t, "weekly", next.Recurrence)
+		require.Equal(t, "B", next.Priority)
+		require.Equal(t, []string{"work"}, next.Projects)
+		require.Nil(t, next.CompletedAt)
+		require.Equal(t, due.AddDate(0, 0, 7), *next.DueDate)
+		require.Equal(t, scheduled.AddDate(0, 0, 7), *next.ScheduledDate)
+	})
+
+	t.Run("task without dates is scheduled for the next day", func(t *testing.T) {
+		completed := time.Date(2025, 1, 3, 10, 0, 0, 0, time.Local)
+
+		task := NewTask("Water plants")
+		task.Recurrence = "+3d"
+		task.Status = StatusDONE
+		task.CompletedAt = &completed
+
+		next := task.NextOccurrence()
+		require.NotNil(t, next)
+		require.Nil(t, next.DueDate)
+		require.Equal(t, time.Date(2025, 1, 6, 0, 0, 0, 0, time.Local), *next.ScheduledDate)
+	})
+}
+
+func TestCompletingRecurringTaskCreatesNextOccurrence(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+
+	task := ParseTask("Weekly report repeat:weekly due:friday +work")
+	require.Equal(t, "weekly", task.Recurrence)
+	require.NoError(t, taskFile.AddTask(task))
+
+	require.NoError(t, taskFile.UpdateTaskWithConflictCheck(task.ID, task.Updated, func(t *Task) {
+		t.SetStatus(StatusDONE)
+	}))
+
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+
+	require.Equal(t, StatusDONE, tasks[0].Status)
+	require.Empty(t, tasks[0].Recurrence, "the rule moves to the next occurrence")
+
+	require.Equal(t, StatusTODO, tasks[1].Status)
+	require.Equal(t, "Weekly report", tasks[1].Title)
+	require.Equal(t, "weekly", tasks[1].Recurrence)
+	require.True(t, task.DueDate.AddDate(0, 0, 7).Equal(*tasks[1].DueDate))
+
+	// Reopening takes back the untouched next occurrence, with its rule
+	require.NoError(t, taskFile.UpdateTaskWithConflictCheck(tasks[0].ID, tasks[0].Updated, func(t *Task) {
+		t.SetStatus(StatusTODO)
+	}))
+	tasks, err = taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Equal(t, "weekly", tasks[0].Recurrence)
+
+	// Completing it again repeats it once, a week after the original
+	require.NoError(t, taskFile.UpdateTaskWithConflictCheck(tasks[0].ID, tasks[0].Updated, func(t *Task) {
+		t.SetStatus(StatusDONE)
+	}))
+	tasks, err = taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	require.True(t, task.DueDate.AddDate(0, 0, 7).Equal(*tasks[1].DueDate))
+
+	// An occurrence changed since is kept
+	require.NoError(t, taskFile.UpdateTaskWithConflictCheck(tasks[1].ID, tasks[1].Updated, func(t *Task) {
+		t.Note = "started"
+	}))
+	require.NoError(t, taskFile.UpdateTaskWithConflictCheck(tasks[0].ID, tasks[0].Updated, func(t *Task) {
+		t.SetStatus(StatusTODO)
+	}))
+	tasks, err = taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	require.Empty(t, tasks[0].Recurrence)
+}
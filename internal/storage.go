@@ -3,18 +3,26 @@ package internal
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/gofrs/flock"
 )
 
+// ErrTaskModified is returned when a task has been updated since the caller loaded it
+var ErrTaskModified = errors.New("task has been modified by another process")
+
 type TaskFile struct {
-	Path string
+	Path  string
+	Actor string // Recorded in the change journal as who made the changes, the user name by default
+
+	collected *[]TaskChange // Changes of the saves while CollectChanges runs
 }
 
 func NewTaskFileForTesting(t *testing.T) *TaskFile {
@@ -111,8 +119,14 @@ func (tf *TaskFile) LoadTasks() ([]Task, error) {
 	return tasks, nil
 }
 
-func (tf *TaskFile) saveTasks(tasks []Task) error {
-	tempFile, err := os.CreateTemp(filepath.Dir(tf.Path), ".taskeru-*.tmp")
+// saveTasks saves the tasks, which were before when loaded under the lock, and journals the changes
+func (tf *TaskFile) saveTasks(before, tasks []Task) error {
+	return tf.saveTasksAs(before, tasks, JournalCreated, JournalDeleted)
+}
+
+// writeTaskLines replaces the file at path with one JSON line per task, through a temporary file
+func writeTaskLines(path string, tempPattern string, tasks []Task) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), tempPattern)
 	if err != nil {
 		return err
 	}
@@ -149,7 +163,7 @@ func (tf *TaskFile) saveTasks(tasks []Task) error {
 		return err
 	}
 
-	if err := os.Rename(tempPath, tf.Path); err != nil {
+	if err := os.Rename(tempPath, path); err != nil {
 		return err
 	}
 
@@ -164,10 +178,17 @@ func (tf *TaskFile) lock() (*flock.Flock, error) {
 	return lock, nil
 }
 
+// AddTask adds a task, which is updated as stored
 func (tf *TaskFile) AddTask(task *Task) error {
-	return tf.AddTasks([]Task{*task})
+	newTasks := []Task{*task}
+	if err := tf.AddTasks(newTasks); err != nil {
+		return err
+	}
+	*task = newTasks[0]
+	return nil
 }
 
+// AddTasks adds tasks and resolves their after:/blocks: references. newTasks are updated as stored.
 func (tf *TaskFile) AddTasks(newTasks []Task) error {
 	lock, err := tf.lock()
 	if err != nil {
@@ -179,12 +200,63 @@ func (tf *TaskFile) AddTasks(newTasks []Task) error {
 	if err != nil {
 		return fmt.Errorf("failed to load tasks: %w", err)
 	}
+	before := cloneTasks(tasks)
 
+	start := len(tasks)
 	tasks = append(tasks, newTasks...)
-	return tf.saveTasks(tasks)
+
+	// Resolve after:/blocks: references of the new tasks
+	var newIDs []string
+	for i := start; i < len(tasks); i++ {
+		keepUnresolvedRefs(tasks, i, nil)
+		if err := linkDependencies(tasks, i); err != nil {
+			return err
+		}
+		newIDs = append(newIDs, tasks[i].ID)
+	}
+	if err := checkDependencyCycles(tasks, newIDs); err != nil {
+		return err
+	}
+
+	if err := tf.saveTasks(before, tasks); err != nil {
+		return err
+	}
+	copy(newTasks, tasks[start:])
+	return nil
 }
 
+// UpdateTaskWithConflictCheck applies updateFunc to the task, unless it has been updated
+// since originalUpdated. Use UpdateTaskWithMerge to combine concurrent edits instead.
 func (tf *TaskFile) UpdateTaskWithConflictCheck(taskID string, originalUpdated time.Time, updateFunc func(*Task)) error {
+	return tf.updateTask(taskID, func(task *Task) error {
+		// Check if the task has been updated since we loaded it
+		if !task.Updated.Equal(originalUpdated) {
+			return fmt.Errorf("%w (%v != %v)", ErrTaskModified, task.Updated, originalUpdated)
+		}
+		updateFunc(task)
+		return nil
+	})
+}
+
+// UpdateTaskWithMerge applies updateFunc to original, the version of the task the caller started from.
+// If another process has updated the task since, the fields changed by updateFunc are merged into the
+// current version (a three-way merge), so edits of different fields are both kept.
+// Fields changed differently on both sides are returned as a *ConflictError and nothing is saved.
+func (tf *TaskFile) UpdateTaskWithMerge(original Task, updateFunc func(*Task)) error {
+	return tf.updateTask(original.ID, func(task *Task) error {
+		if task.Updated.Equal(original.Updated) {
+			updateFunc(task)
+			return nil
+		}
+		edited := cloneTask(original)
+		updateFunc(&edited)
+		return mergeTaskFields(task, original, edited)
+	})
+}
+
+// updateTask loads the tasks, lets apply change the task and saves the tasks,
+// taking care of dependencies, completion of blockers and recurrence
+func (tf *TaskFile) updateTask(taskID string, apply func(*Task) error) error {
 	lock, err := tf.lock()
 	if err != nil {
 		return fmt.Errorf("failed to lock task file: %w", err)
@@ -195,18 +267,47 @@ func (tf *TaskFile) UpdateTaskWithConflictCheck(taskID string, originalUpdated t
 	if err != nil {
 		return err
 	}
+	before := cloneTasks(tasks)
 
 	found := false
 	for i := range tasks {
 		if tasks[i].ID == taskID {
-			// Check if the task has been updated since we loaded it
-			if !tasks[i].Updated.Equal(originalUpdated) {
-				return fmt.Errorf("task has been modified by another process(%v != %v)",
-					tasks[i].Updated, originalUpdated)
+			oldStatus := tasks[i].Status
+			oldBlockedBy := slices.Clone(tasks[i].BlockedBy)
+			if err := apply(&tasks[i]); err != nil {
+				return err
+			}
+
+			// Newly added blockers may put the task into WAITING
+			if !slices.Equal(oldBlockedBy, tasks[i].BlockedBy) || len(tasks[i].Blocks) > 0 {
+				keepUnresolvedRefs(tasks, i, oldBlockedBy)
+				if err := linkDependencies(tasks, i); err != nil {
+					return err
+				}
+				if err := checkDependencyCycles(tasks, []string{taskID}); err != nil {
+					return err
+				}
 			}
-			updateFunc(&tasks[i])
+
 			tasks[i].Updated = time.Now()
 			found = true
+
+			// Finishing a blocker releases the tasks waiting for it
+			if !isCompletedStatus(oldStatus) && tasks[i].IsCompleted() {
+				releaseDependents(tasks, tasks[i].ID)
+			}
+
+			// Completing a recurring task brings back its next occurrence.
+			// The rule moves to the new task so toggling DONE again doesn't duplicate it.
+			if oldStatus != StatusDONE && tasks[i].Status == StatusDONE {
+				if next := tasks[i].NextOccurrence(); next != nil {
+					tasks[i].Recurrence = ""
+					tasks = append(tasks, *next)
+				}
+			}
+			if oldStatus == StatusDONE && !tasks[i].IsCompleted() {
+				tasks = takeBackOccurrence(tasks, i)
+			}
 			break
 		}
 	}
@@ -215,7 +316,7 @@ func (tf *TaskFile) UpdateTaskWithConflictCheck(taskID string, originalUpdated t
 		return fmt.Errorf("task with ID %s not found", taskID)
 	}
 
-	return tf.saveTasks(tasks)
+	return tf.saveTasks(before, tasks)
 }
 
 // saveDeletedTasksToTrash saves deleted tasks to trash.json
@@ -224,82 +325,38 @@ func (tf *TaskFile) saveDeletedTasksToTrash(deletedTasks []Task) error {
 		return nil
 	}
 
-	trashPath := tf.getTrashFilePath()
-
 	// Load existing trash tasks
-	existingTrash := []Task{}
-	file, err := os.Open(trashPath)
-	if err == nil {
-		defer func() { _ = file.Close() }()
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if line == "" {
-				continue
-			}
-			var task Task
-			if err := json.Unmarshal([]byte(line), &task); err == nil {
-				existingTrash = append(existingTrash, task)
-			}
-		}
+	existingTrash, err := tf.loadTrash()
+	if err != nil {
+		return err
 	}
 
 	// Mark deleted tasks with deletion time
 	now := time.Now()
 	for i := range deletedTasks {
-		// Store deletion time in Updated field
-		deletedTasks[i].Updated = now
+		deletedTasks[i].DeletedAt = &now
 	}
 
 	// Append deleted tasks to existing trash
-	allTrash := append(existingTrash, deletedTasks...)
-
-	// Write all trash tasks
-	tempFile, err := os.CreateTemp(filepath.Dir(trashPath), ".trash-*.tmp")
-	if err != nil {
-		return err
-	}
-	tempPath := tempFile.Name()
+	return tf.saveTrash(append(existingTrash, deletedTasks...))
+}
 
-	defer func() {
-		_ = tempFile.Close()
-		_ = os.Remove(tempPath)
-	}()
+func (tf *TaskFile) DeleteTask(taskID string) error {
+	return tf.deleteTask(taskID, nil)
+}
 
-	writer := bufio.NewWriter(tempFile)
-	for _, task := range allTrash {
-		jsonData, err := json.Marshal(task)
-		if err != nil {
-			return err
-		}
-		if _, err := writer.Write(jsonData); err != nil {
-			return err
+// DeleteTaskWithConflictCheck deletes the task, unless it has been updated since originalUpdated
+func (tf *TaskFile) DeleteTaskWithConflictCheck(taskID string, originalUpdated time.Time) error {
+	return tf.deleteTask(taskID, func(task *Task) error {
+		if !task.Updated.Equal(originalUpdated) {
+			return fmt.Errorf("%w (%v != %v)", ErrTaskModified, task.Updated, originalUpdated)
 		}
-		if _, err := writer.WriteString("\n"); err != nil {
-			return err
-		}
-	}
-
-	if err := writer.Flush(); err != nil {
-		return err
-	}
-
-	if err := tempFile.Sync(); err != nil {
-		return err
-	}
-
-	if err := tempFile.Close(); err != nil {
-		return err
-	}
-
-	if err := os.Rename(tempPath, trashPath); err != nil {
-		return err
-	}
-
-	return nil
+		return nil
+	})
 }
 
-func (tf *TaskFile) DeleteTask(taskID string) error {
+// deleteTask moves the task to the trash, if check, called under the lock, accepts it
+func (tf *TaskFile) deleteTask(taskID string, check func(*Task) error) error {
 	lock, err := tf.lock()
 	if err != nil {
 		return fmt.Errorf("failed to lock task file: %w", err)
@@ -317,6 +374,11 @@ func (tf *TaskFile) DeleteTask(taskID string) error {
 	)
 	for _, task := range tasks {
 		if task.ID == taskID {
+			if check != nil {
+				if err := check(&task); err != nil {
+					return err
+				}
+			}
 			deleted = append(deleted, task)
 		} else {
 			remaining = append(remaining, task)
@@ -327,11 +389,15 @@ func (tf *TaskFile) DeleteTask(taskID string) error {
 		return fmt.Errorf("task with ID %s not found", taskID)
 	}
 
+	// Deleted blockers don't block anymore
+	releaseDependents(remaining, taskID)
+
 	if err := tf.saveDeletedTasksToTrash(deleted); err != nil {
 		return fmt.Errorf("failed to save deleted tasks to trash: %w", err)
 	}
 
-	if err := tf.saveTasks(remaining); err != nil {
+	// remaining has copies of the tasks, so tasks are still as loaded
+	if err := tf.saveTasks(tasks, remaining); err != nil {
 		return fmt.Errorf("failed to save tasks: %w", err)
 	}
 
@@ -215,6 +215,25 @@ func TestDeleteTask(t *testing.T) {
 	require.True(t, found, "Deleted task should be in trash")
 }
 
+func TestDeleteTaskWithConflictCheck(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	task := NewTask("Changed meanwhile")
+	require.NoError(t, taskFile.AddTask(task))
+	require.NoError(t, taskFile.UpdateTaskWithConflictCheck(task.ID, task.Updated, func(t *Task) {
+		t.Note = "edited"
+	}))
+
+	// The version the caller saw is out of date, so nothing is deleted
+	err := taskFile.DeleteTaskWithConflictCheck(task.ID, task.Updated)
+	require.ErrorIs(t, err, ErrTaskModified)
+	current := loadTaskByID(t, taskFile, task.ID)
+
+	require.NoError(t, taskFile.DeleteTaskWithConflictCheck(task.ID, current.Updated))
+	loaded, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Empty(t, loaded)
+}
+
 // splitLines is a helper for splitting file content into lines
 func splitLines(s string) []string {
 	var lines []string
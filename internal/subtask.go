@@ -0,0 +1,125 @@
+package internal
+
+// arrangeTaskTree reorders already sorted tasks so that each subtask follows its parent.
+// Tasks whose parent is not in the list are treated as top-level tasks.
+func arrangeTaskTree(tasks []Task) {
+	present := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		present[task.ID] = true
+	}
+
+	children := make(map[string][]Task)
+	var roots []Task
+	for _, task := range tasks {
+		if task.ParentID != "" && task.ParentID != task.ID && present[task.ParentID] {
+			children[task.ParentID] = append(children[task.ParentID], task)
+		} else {
+			roots = append(roots, task)
+		}
+	}
+
+	ordered := make([]Task, 0, len(tasks))
+	visited := make(map[string]bool, len(tasks))
+	var walk func(task Task)
+	walk = func(task Task) {
+		if visited[task.ID] {
+			return
+		}
+		visited[task.ID] = true
+		ordered = append(ordered, task)
+		for _, child := range children[task.ID] {
+			walk(child)
+		}
+	}
+	for _, task := range roots {
+		walk(task)
+	}
+
+	// Tasks in a parent cycle are never reached from a root; keep them at the end
+	for _, task := range tasks {
+		if !visited[task.ID] {
+			walk(task)
+		}
+	}
+
+	copy(tasks, ordered)
+}
+
+// TaskDepths returns the nesting level of each task, counting only parents present in the list
+func TaskDepths(tasks []Task) map[string]int {
+	parents := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		parents[task.ID] = task.ParentID
+	}
+
+	depths := make(map[string]int, len(tasks))
+	for _, task := range tasks {
+		depth := 0
+		seen := map[string]bool{task.ID: true}
+		for parentID := task.ParentID; parentID != ""; parentID = parents[parentID] {
+			if _, ok := parents[parentID]; !ok || seen[parentID] {
+				break
+			}
+			seen[parentID] = true
+			depth++
+		}
+		depths[task.ID] = depth
+	}
+	return depths
+}
+
+// GetSubtasks returns the direct children of a task
+func GetSubtasks(tasks []Task, parentID string) []Task {
+	var subtasks []Task
+	for _, task := range tasks {
+		if task.ParentID == parentID && task.ID != parentID {
+			subtasks = append(subtasks, task)
+		}
+	}
+	return subtasks
+}
+
+// GetOpenDescendants returns all subtasks below a task, at any depth, which are not completed yet
+func GetOpenDescendants(tasks []Task, parentID string) []Task {
+	var result []Task
+	visited := map[string]bool{parentID: true}
+	queue := []string{parentID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, child := range GetSubtasks(tasks, id) {
+			if visited[child.ID] {
+				continue
+			}
+			visited[child.ID] = true
+			queue = append(queue, child.ID)
+			if !child.IsCompleted() {
+				result = append(result, child)
+			}
+		}
+	}
+	return result
+}
+
+// SubtaskProgress counts how many direct subtasks are completed
+type SubtaskProgress struct {
+	Done  int
+	Total int
+}
+
+// GetSubtaskProgress returns the subtask progress of every task which has subtasks
+func GetSubtaskProgress(tasks []Task) map[string]SubtaskProgress {
+	progress := make(map[string]SubtaskProgress)
+	for _, task := range tasks {
+		if task.ParentID == "" || task.ParentID == task.ID {
+			continue
+		}
+		p := progress[task.ParentID]
+		p.Total++
+		if task.IsCompleted() {
+			p.Done++
+		}
+		progress[task.ParentID] = p
+	}
+	return progress
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func taskIDs(tasks []Task) []string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func TestSortTasksPlacesSubtasksAfterParent(t *testing.T) {
+	now := time.Now()
+	tasks := []Task{
+		{ID: "1", Status: StatusTODO, Priority: "B", Updated: now},
+		{ID: "2", Status: StatusTODO, Priority: "A", Updated: now},
+		{ID: "3", Status: StatusTODO, Priority: "A", Updated: now, ParentID: "1"},
+		{ID: "4", Status: StatusTODO, Priority: "C", Updated: now, ParentID: "1"},
+		{ID: "5", Status: StatusDONE, Updated: now, ParentID: "2"},
+		{ID: "6", Status: StatusTODO, Updated: now, ParentID: "3"},
+		{ID: "7", Status: StatusTODO, Priority: "A", Updated: now, ParentID: "missing"},
+	}
+
+	SortTasks(tasks)
+
+	require.Equal(t, []string{"7", "2", "5", "1", "3", "6", "4"}, taskIDs(tasks))
+}
+
+func TestSortTasksWithParentCycle(t *testing.T) {
+	now := time.Now()
+	tasks := []Task{
+		{ID: "1", Status: StatusTODO, Updated: now, ParentID: "2"},
+		{ID: "2", Status: StatusTODO, Updated: now, ParentID: "1"},
+		{ID: "3", Status: StatusTODO, Updated: now},
+	}
+
+	SortTasks(tasks)
+
+	require.Len(t, tasks, 3)
+	require.ElementsMatch(t, []string{"1", "2", "3"}, taskIDs(tasks))
+}
+
+func TestTaskDepths(t *testing.T) {
+	tasks := []Task{
+		{ID: "1"},
+		{ID: "2", ParentID: "1"},
+		{ID: "3", ParentID: "2"},
+		{ID: "4", ParentID: "missing"},
+	}
+
+	depths := TaskDepths(tasks)
+
+	require.Equal(t, map[string]int{"1": 0, "2": 1, "3": 2, "4": 0}, depths)
+}
+
+func TestGetSubtaskProgress(t *testing.T) {
+	tasks := []Task{
+		{ID: "1", Status: StatusTODO},
+		{ID: "2", Status: StatusDONE, ParentID: "1"},
+		{ID: "3", Status: StatusWONTDO, ParentID: "1"},
+		{ID: "4", Status: StatusTODO, ParentID: "1"},
+		{ID: "5", Status: StatusTODO, ParentID: "4"},
+	}
+
+	progress := GetSubtaskProgress(tasks)
+
+	require.Equal(t, SubtaskProgress{Done: 2, Total: 3}, progress["1"])
+	require.Equal(t, SubtaskProgress{Done: 0, Total: 1}, progress["4"])
+	require.Equal(t, SubtaskProgress{}, progress["2"])
+}
+
+func TestGetOpenDescendants(t *testing.T) {
+	tasks := []Task{
+		{ID: "1", Status: StatusTODO},
+		{ID: "2", Status: StatusDONE, ParentID: "1"},
+		{ID: "3", Status: StatusTODO, ParentID: "1"},
+		{ID: "4", Status: StatusDOING, ParentID: "2"},
+		{ID: "5", Status: StatusTODO},
+	}
+
+	require.ElementsMatch(t, []string{"3", "4"}, taskIDs(GetOpenDescendants(tasks, "1")))
+	require.Empty(t, GetOpenDescendants(tasks, "5"))
+}
@@ -0,0 +1,299 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MergeTasks merges two versions of a task list which were changed concurrently from base.
+// Tasks are matched by ID, and a task changed on both sides takes the version updated last
+// (ours on a tie). A task deleted on one side is deleted, unless the other side changed it.
+// The result keeps the order of ours, followed by the tasks only theirs has.
+func MergeTasks(base, ours, theirs []Task) []Task {
+	baseByID := tasksByID(base)
+	oursByID := tasksByID(ours)
+	theirsByID := tasksByID(theirs)
+
+	// deleted reports whether the other side deleted a task which this side didn't change
+	deleted := func(task Task, other map[string]Task) bool {
+		baseTask, inBase := baseByID[task.ID]
+		_, inOther := other[task.ID]
+		return inBase && !inOther && task.Updated.Equal(baseTask.Updated)
+	}
+
+	var merged []Task
+	for _, task := range ours {
+		if deleted(task, theirsByID) {
+			continue
+		}
+		if their, ok := theirsByID[task.ID]; ok && their.Updated.After(task.Updated) {
+			task = their
+		}
+		merged = append(merged, task)
+	}
+	for _, task := range theirs {
+		if _, ok := oursByID[task.ID]; ok || deleted(task, oursByID) {
+			continue
+		}
+		merged = append(merged, task)
+	}
+	return merged
+}
+
+func tasksByID(tasks []Task) map[string]Task {
+	byID := make(map[string]Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+	return byID
+}
+
+// MergeTaskFiles is a git merge driver: it merges the task files at basePath, oursPath and
+// theirsPath with MergeTasks and writes the result to oursPath, as git expects.
+func MergeTaskFiles(basePath, oursPath, theirsPath string) error {
+	var versions [3][]Task
+	for i, path := range []string{basePath, oursPath, theirsPath} {
+		tasks, err := readTaskLines(path)
+		if err != nil {
+			return err
+		}
+		versions[i] = tasks
+	}
+	return writeTaskLines(oursPath, ".taskeru-merge-*.tmp", MergeTasks(versions[0], versions[1], versions[2]))
+}
+
+// SyncResult tells what Sync did
+type SyncResult struct {
+	Committed bool   // Local changes of the task file were committed
+	Upstream  string // e.g. origin/main
+	Pulled    string // "", "fast-forward" or "merge"
+	Pushed    bool
+}
+
+// Sync commits the task file, pulls from the upstream branch of its git repository and pushes.
+// When both sides have new commits, the task file is merged with MergeTasks rather than line by line,
+// so concurrent edits of different tasks, or of the same task, don't conflict.
+// The task file is locked meanwhile, so other taskeru processes wait for the sync.
+// Pulled changes are recorded in the change journal, which itself isn't synced.
+func (tf *TaskFile) Sync() (SyncResult, error) {
+	var result SyncResult
+
+	lock, err := tf.lock()
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	dir := filepath.Dir(tf.Path)
+	name := filepath.Base(tf.Path)
+	// "./" makes REV:path relative to dir rather than to the top of the repository
+	revPath := "./" + name
+
+	if _, err := git(dir, "rev-parse", "--git-dir"); err != nil {
+		return result, fmt.Errorf("%s is not in a git repository", tf.Path)
+	}
+
+	if _, err := git(dir, "add", "--", name); err != nil {
+		return result, err
+	}
+	if _, err := git(dir, "diff", "--cached", "--quiet", "--", name); err != nil {
+		if _, err := git(dir, "commit", "-m", "taskeru sync", "--", name); err != nil {
+			return result, err
+		}
+		result.Committed = true
+	}
+
+	upstream, err := git(dir, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
+	if err != nil {
+		return result, fmt.Errorf("the current branch has no upstream branch (set one with git push -u): %w", err)
+	}
+	result.Upstream = upstream
+
+	if _, err := git(dir, "fetch", "--quiet"); err != nil {
+		return result, err
+	}
+
+	// The local changes are committed, so the pulled changes are journaled against HEAD
+	before, err := tf.LoadTasks()
+	if err != nil {
+		return result, err
+	}
+
+	switch {
+	case isAncestor(dir, "@{u}", "HEAD"):
+		// Nothing new upstream
+	case isAncestor(dir, "HEAD", "@{u}"):
+		if _, err := git(dir, "merge", "--quiet", "--ff-only", "@{u}"); err != nil {
+			return result, err
+		}
+		result.Pulled = "fast-forward"
+	default:
+		if err := mergeUpstream(dir, revPath, tf.Path); err != nil {
+			return result, err
+		}
+		result.Pulled = "merge"
+	}
+	if result.Pulled != "" {
+		after, err := tf.LoadTasks()
+		if err != nil {
+			return result, err
+		}
+		tf.journal(DiffTasks(before, after), JournalCreated, JournalDeleted)
+	}
+
+	ahead, err := git(dir, "rev-list", "--count", "@{u}..HEAD")
+	if err != nil {
+		return result, err
+	}
+	if count, _ := strconv.Atoi(ahead); count > 0 {
+		if _, err := git(dir, "push", "--quiet"); err != nil {
+			return result, err
+		}
+		result.Pushed = true
+	}
+	return result, nil
+}
+
+// mergeUpstream merges the upstream branch, resolving the task file with MergeTasks.
+// The merge is aborted if other files conflict.
+func mergeUpstream(dir, revPath, path string) error {
+	mergeBase, err := git(dir, "merge-base", "HEAD", "@{u}")
+	if err != nil {
+		return err
+	}
+
+	var versions [3][]Task
+	for i, rev := range []string{mergeBase, "HEAD", "@{u}"} {
+		if versions[i], err = gitTasks(dir, rev, revPath); err != nil {
+			return err
+		}
+	}
+
+	// Conflicts are expected here and resolved below, but the merge may also not start at all,
+	// e.g. because of local changes of other files
+	_, mergeErr := git(dir, "merge", "--quiet", "--no-ff", "--no-commit", "@{u}")
+	if _, err := git(dir, "rev-parse", "--verify", "--quiet", "MERGE_HEAD"); err != nil {
+		if mergeErr == nil {
+			mergeErr = fmt.Errorf("git merge did not start")
+		}
+		return mergeErr
+	}
+
+	if err := writeTaskLines(path, ".taskeru-merge-*.tmp", MergeTasks(versions[0], versions[1], versions[2])); err != nil {
+		_, _ = git(dir, "merge", "--abort")
+		return fmt.Errorf("failed to write merged tasks: %w", err)
+	}
+	if _, err := git(dir, "add", "--", revPath); err != nil {
+		_, _ = git(dir, "merge", "--abort")
+		return err
+	}
+
+	if unmerged, _ := git(dir, "diff", "--name-only", "--diff-filter=U"); unmerged != "" {
+		_, _ = git(dir, "merge", "--abort")
+		return fmt.Errorf("merge conflicts outside the task file, resolve them with git: %s",
+			strings.ReplaceAll(unmerged, "\n", ", "))
+	}
+
+	_, err = git(dir, "commit", "--quiet", "--no-edit")
+	return err
+}
+
+// gitTasks reads the task file at a revision. A revision without the file has no tasks.
+func gitTasks(dir, rev, revPath string) ([]Task, error) {
+	if _, err := git(dir, "rev-parse", "--verify", "--quiet", rev+":"+revPath); err != nil {
+		return nil, nil
+	}
+	content, err := git(dir, "show", rev+":"+revPath)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTaskLines(strings.NewReader(content), rev+":"+revPath)
+}
+
+// InstallMergeDriver configures the git repository of the task file to merge it with
+// `<executable> merge-driver`, so that plain git merge and git pull merge it per task too.
+// The attribute is written to .git/info/attributes, which isn't shared with other clones.
+func (tf *TaskFile) InstallMergeDriver(executable string) error {
+	dir := filepath.Dir(tf.Path)
+
+	prefix, err := git(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return fmt.Errorf("%s is not in a git repository", tf.Path)
+	}
+
+	quoted := "'" + strings.ReplaceAll(executable, "'", `'\''`) + "'"
+	if _, err := git(dir, "config", "merge.taskeru.name", "taskeru per-task merge"); err != nil {
+		return err
+	}
+	// Without -l, the log file would be written into the repository
+	if _, err := git(dir, "config", "merge.taskeru.driver", quoted+" -l '' merge-driver %O %A %B"); err != nil {
+		return err
+	}
+
+	attributesPath, err := git(dir, "rev-parse", "--git-path", "info/attributes")
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(attributesPath) {
+		attributesPath = filepath.Join(dir, attributesPath)
+	}
+	return appendLineOnce(attributesPath, "/"+prefix+filepath.Base(tf.Path)+" merge=taskeru")
+}
+
+// appendLineOnce appends line to the file at path, unless the file already has it
+func appendLineOnce(path, line string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, existing := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(existing) == line {
+			return nil
+		}
+	}
+
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		line = "\n" + line
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(line + "\n"); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// isAncestor reports whether commit a is an ancestor of (or the same as) commit b
+func isAncestor(dir, a, b string) bool {
+	_, err := git(dir, "merge-base", "--is-ancestor", a, b)
+	return err == nil
+}
+
+// git runs a git command in dir and returns its output without the trailing newline
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
@@ -0,0 +1,200 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeTasks(t *testing.T) {
+	t0 := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	task := func(id, title string, updated time.Time) Task {
+		return Task{ID: id, Title: title, Status: StatusTODO, Updated: updated}
+	}
+	base := []Task{
+		task("1", "Unchanged", t0),
+		task("2", "Both changed", t0),
+		task("3", "Deleted by them", t0),
+		task("4", "Deleted by them, changed by us", t0),
+		task("5", "Deleted by us", t0),
+	}
+	ours := []Task{
+		task("1", "Unchanged", t0),
+		task("2", "Both changed, ours", t0.Add(2*time.Hour)),
+		task("3", "Deleted by them", t0),
+		task("4", "Changed by us", t0.Add(time.Hour)),
+		task("6", "Added by us", t0),
+	}
+	theirs := []Task{
+		task("7", "Added by them", t0),
+		task("1", "Unchanged", t0),
+		task("2", "Both changed, theirs", t0.Add(time.Hour)),
+		task("5", "Deleted by us", t0),
+	}
+
+	var titles []string
+	for _, task := range MergeTasks(base, ours, theirs) {
+		titles = append(titles, task.Title)
+	}
+	require.Equal(t, []string{"Unchanged", "Both changed, ours", "Changed by us", "Added by us", "Added by them"}, titles)
+
+	// The later update wins, whichever side it is on
+	theirs[2].Updated = t0.Add(3 * time.Hour)
+	require.Equal(t, "Both changed, theirs", MergeTasks(base, ours, theirs)[1].Title)
+}
+
+func TestMergeTaskFiles(t *testing.T) {
+	dir := t.TempDir()
+	t0 := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	write := func(name string, tasks ...Task) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, writeTaskLines(path, ".test-*.tmp", tasks))
+		return path
+	}
+	base := write("base", Task{ID: "1", Title: "Old", Updated: t0})
+	ours := write("ours", Task{ID: "1", Title: "Ours", Updated: t0.Add(time.Hour)})
+	theirs := write("theirs", Task{ID: "1", Title: "Old", Updated: t0}, Task{ID: "2", Title: "New", Updated: t0})
+
+	require.NoError(t, MergeTaskFiles(base, ours, theirs))
+
+	merged, err := readTaskLines(ours)
+	require.NoError(t, err)
+	require.Len(t, merged, 2)
+	require.Equal(t, "Ours", merged[0].Title)
+	require.Equal(t, "New", merged[1].Title)
+}
+
+// newSyncClones creates a bare repository with a task file and two clones of it
+func newSyncClones(t *testing.T) (*TaskFile, *TaskFile) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	run := func(dir string, args ...string) {
+		t.Helper()
+		_, err := git(dir, args...)
+		require.NoError(t, err)
+	}
+
+	remote := filepath.Join(root, "remote.git")
+	run(root, "init", "--quiet", "--bare", "--initial-branch=main", remote)
+
+	var clones []*TaskFile
+	for _, name := range []string{"laptop", "desktop"} {
+		dir := filepath.Join(root, name)
+		run(root, "clone", "--quiet", remote, dir)
+		run(dir, "config", "user.name", "Test")
+		run(dir, "config", "user.email", "test@example.com")
+		run(dir, "checkout", "--quiet", "-B", "main")
+		clones = append(clones, NewTaskFileWithPath(filepath.Join(dir, "todo.json")))
+	}
+
+	// The initial commit sets up the branch on the remote
+	laptop := clones[0]
+	require.NoError(t, laptop.AddTask(NewTask("Shared task")))
+	run(filepath.Dir(laptop.Path), "add", "todo.json")
+	run(filepath.Dir(laptop.Path), "commit", "--quiet", "-m", "Initial tasks")
+	run(filepath.Dir(laptop.Path), "push", "--quiet", "-u", "origin", "main")
+	run(filepath.Dir(clones[1].Path), "pull", "--quiet", "origin", "main")
+	run(filepath.Dir(clones[1].Path), "branch", "--quiet", "--set-upstream-to=origin/main")
+
+	return clones[0], clones[1]
+}
+
+func TestSyncMergesPerTask(t *testing.T) {
+	laptop, desktop := newSyncClones(t)
+	desktop.Actor = "bob (sync)"
+
+	// Both sides change the same task and add a task at the end of the file,
+	// which conflicts when merged line by line
+	tasks, err := laptop.LoadTasks()
+	require.NoError(t, err)
+	shared := tasks[0]
+	require.NoError(t, laptop.UpdateTaskWithConflictCheck(shared.ID, shared.Updated, func(task *Task) {
+		task.Title = "Shared task, edited on the laptop"
+	}))
+	require.NoError(t, laptop.AddTask(NewTask("Laptop task")))
+
+	result, err := laptop.Sync()
+	require.NoError(t, err)
+	require.True(t, result.Committed)
+	require.True(t, result.Pushed)
+	require.Equal(t, "origin/main", result.Upstream)
+
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, desktop.UpdateTaskWithConflictCheck(shared.ID, shared.Updated, func(task *Task) {
+		task.Title = "Shared task, edited on the desktop"
+	}))
+	require.NoError(t, desktop.AddTask(NewTask("Desktop task")))
+
+	result, err = desktop.Sync()
+	require.NoError(t, err)
+	require.Equal(t, "merge", result.Pulled)
+	require.True(t, result.Pushed)
+
+	tasks, err = desktop.LoadTasks()
+	require.NoError(t, err)
+	var titles []string
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+	}
+	// The desktop edited the shared task last
+	require.Equal(t, []string{"Shared task, edited on the desktop", "Desktop task", "Laptop task"}, titles)
+
+	// The pulled task is journaled as created by the sync
+	entries, err := desktop.LoadJournal(tasks[2].ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, JournalCreated, entries[0].Field)
+	require.Equal(t, "bob (sync)", entries[0].Actor)
+
+	// The laptop gets the merge as a fast-forward
+	result, err = laptop.Sync()
+	require.NoError(t, err)
+	require.False(t, result.Committed)
+	require.Equal(t, "fast-forward", result.Pulled)
+	require.False(t, result.Pushed)
+	laptopTasks, err := laptop.LoadTasks()
+	require.NoError(t, err)
+	require.Equal(t, tasks, laptopTasks)
+
+	result, err = laptop.Sync()
+	require.NoError(t, err)
+	require.Equal(t, SyncResult{Upstream: "origin/main"}, result)
+}
+
+func TestSyncOutsideGitRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	taskFile := NewTaskFileForTesting(t)
+	_, err := taskFile.Sync()
+	require.ErrorContains(t, err, "not in a git repository")
+}
+
+func TestInstallMergeDriver(t *testing.T) {
+	laptop, _ := newSyncClones(t)
+	dir := filepath.Dir(laptop.Path)
+
+	require.NoError(t, laptop.InstallMergeDriver("/opt/task eru/taskeru"))
+	// Installing again doesn't add the attribute twice
+	require.NoError(t, laptop.InstallMergeDriver("/opt/task eru/taskeru"))
+
+	driver, err := git(dir, "config", "merge.taskeru.driver")
+	require.NoError(t, err)
+	require.Equal(t, "'/opt/task eru/taskeru' -l '' merge-driver %O %A %B", driver)
+
+	attributes, err := os.ReadFile(filepath.Join(dir, ".git", "info", "attributes"))
+	require.NoError(t, err)
+	require.Equal(t, "/todo.json merge=taskeru\n", string(attributes))
+
+	attribute, err := git(dir, "check-attr", "merge", "--", "todo.json")
+	require.NoError(t, err)
+	require.Equal(t, "todo.json: merge: taskeru", attribute)
+}
@@ -10,17 +10,33 @@ import (
 )
 
 type Task struct {
-	ID            string     `json:"id"`
-	Title         string     `json:"title"`
-	Created       time.Time  `json:"created"`
-	Updated       time.Time  `json:"updated"`
-	CompletedAt   *time.Time `json:"completed_at,omitempty"`
-	DueDate       *time.Time `json:"due_date,omitempty"`
-	ScheduledDate *time.Time `json:"scheduled_date,omitempty"`
-	Priority      string     `json:"priority,omitempty"`
-	Status        string     `json:"status"`
-	Note          string     `json:"note,omitempty"`
-	Projects      []string   `json:"projects,omitempty"`
+	ID              string      `json:"id"`
+	Title           string      `json:"title"`
+	Created         time.Time   `json:"created"`
+	Updated         time.Time   `json:"updated"`
+	CompletedAt     *time.Time  `json:"completed_at,omitempty"`
+	DueDate         *time.Time  `json:"due_date,omitempty"`
+	ScheduledDate   *time.Time  `json:"scheduled_date,omitempty"`
+	Priority        string      `json:"priority,omitempty"`
+	Status          string      `json:"status"`
+	Note            string      `json:"note,omitempty"`
+	Projects        []string    `json:"projects,omitempty"`
+	Recurrence      string      `json:"recurrence,omitempty"`
+	RecurredFrom    string      `json:"recurred_from,omitempty"` // Completed task this occurrence repeats
+	ParentID        string      `json:"parent_id,omitempty"`
+	BlockedBy       []string    `json:"blocked_by,omitempty"`
+	TimeLog         []TimeEntry `json:"time_log,omitempty"`
+	EstimateMinutes int         `json:"estimate_minutes,omitempty"` // Estimated effort, est:2h in titles
+	Points          float64     `json:"points,omitempty"`           // Effort points, pts:3 in titles
+	DeletedAt       *time.Time  `json:"deleted_at,omitempty"`       // Only set on tasks in the trash file
+
+	// Blocks holds references to tasks which should wait for this one.
+	// It is only used when adding a task (blocks:id) and is never persisted.
+	Blocks []string `json:"-"`
+
+	// TypedTitle is the title as typed, when it had after:/blocks: references. It is used to put
+	// references which match no task back where they were, and is never persisted.
+	TypedTitle string `json:"-"`
 }
 
 // Available task statuses
@@ -131,6 +147,13 @@ func (t *Task) SetStatus(status string) {
 		// Clear completion time when unmarking as done/wontdo
 		t.CompletedAt = nil
 	}
+
+	// Track the time spent while the task is DOING
+	if status == StatusDOING && oldStatus != StatusDOING {
+		t.startTimer(now)
+	} else if status != StatusDOING && oldStatus == StatusDOING {
+		t.stopTimer(now)
+	}
 }
 
 func (t *Task) SetDueDate(dueDate time.Time) {
@@ -149,6 +172,15 @@ func (t *Task) DisplayPriority() string {
 	return "[" + t.Priority + "]"
 }
 
+// IsCompleted returns true if the task is DONE or WONTDO
+func (t *Task) IsCompleted() bool {
+	return isCompletedStatus(t.Status)
+}
+
+func isCompletedStatus(status string) bool {
+	return status == StatusDONE || status == StatusWONTDO
+}
+
 func (t *Task) IsOldCompleted() bool {
 	if (t.Status != StatusDONE && t.Status != StatusWONTDO) || t.CompletedAt == nil {
 		return false
@@ -195,6 +227,32 @@ func FilterVisibleTasks(tasks []Task, showAll bool) []Task {
 	return visible
 }
 
+// ResolveTaskID finds the task ID referenced by ref, which is either a full ID or a unique ID prefix
+func ResolveTaskID(tasks []Task, ref string) (string, error) {
+	if ref == "" {
+		return "", fmt.Errorf("empty task reference")
+	}
+
+	var matches []string
+	for _, task := range tasks {
+		if task.ID == ref {
+			return task.ID, nil
+		}
+		if strings.HasPrefix(task.ID, ref) {
+			matches = append(matches, task.ID)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no task matches %q", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("task reference %q is ambiguous (%d tasks match)", ref, len(matches))
+	}
+}
+
 // GetAllProjects returns all unique projects from a list of tasks
 func GetAllProjects(tasks []Task) []string {
 	projectMap := make(map[string]bool)
@@ -243,6 +301,7 @@ func GetPriorityValue(priority string) float64 {
 }
 
 // SortTasks sorts tasks by status (active first, completed last), then priority (A-Z), then update time, then ID (descending)
+// Subtasks are placed right after their parent, sorted by the same rules among their siblings.
 func SortTasks(tasks []Task) {
 	sort.Slice(tasks, func(i, j int) bool {
 		iCompleted := tasks[i].Status == StatusDONE || tasks[i].Status == StatusWONTDO
@@ -270,6 +329,8 @@ func SortTasks(tasks []Task) {
 		// If all else is equal, sort by ID descending (newest first)
 		return tasks[i].ID > tasks[j].ID
 	})
+
+	arrangeTaskTree(tasks)
 }
 
 // GetProjectColor returns an ANSI 256 color code for a project name
@@ -254,3 +254,39 @@ func TestSortTasks(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveTaskID(t *testing.T) {
+	tasks := []Task{
+		{ID: "0198aaaa-1111"},
+		{ID: "0198aaaa-2222"},
+		{ID: "0198bbbb-3333"},
+	}
+
+	tests := []struct {
+		ref      string
+		expected string
+		wantErr  bool
+	}{
+		{ref: "0198aaaa-1111", expected: "0198aaaa-1111"},
+		{ref: "0198b", expected: "0198bbbb-3333"},
+		{ref: "0198aaaa-2", expected: "0198aaaa-2222"},
+		{ref: "0198aaaa", wantErr: true},
+		{ref: "ffff", wantErr: true},
+		{ref: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			id, err := ResolveTaskID(tasks, tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ResolveTaskID(%q) expected error, got %q", tt.ref, id)
+				}
+				return
+			}
+			if err != nil || id != tt.expected {
+				t.Errorf("ResolveTaskID(%q) = %q, %v, want %q", tt.ref, id, err, tt.expected)
+			}
+		})
+	}
+}
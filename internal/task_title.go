@@ -7,7 +7,15 @@ import (
 )
 
 func ParseTask(title string) *Task {
-	cleanTitle, scheduled := ExtractScheduledDateFromTitle(title)
+	return parseTask(title, looksLikeTaskID)
+}
+
+// parseTask parses the title, taking the after:/blocks: words accepted by isRef as task references
+func parseTask(title string, isRef func(string) bool) *Task {
+	cleanTitle, recurrence := ExtractRecurrenceFromTitle(title)
+	cleanTitle, blockedBy, blocks := extractDependencies(cleanTitle, isRef)
+	cleanTitle, estimate, points := ExtractEffortFromTitle(cleanTitle)
+	cleanTitle, scheduled := ExtractScheduledDateFromTitle(cleanTitle)
 	cleanTitle, deadline := ExtractDeadlineFromTitle(cleanTitle)
 	cleanTitle, projects := ExtractProjectsFromTitle(cleanTitle)
 
@@ -15,10 +23,79 @@ func ParseTask(title string) *Task {
 	task.Projects = projects
 	task.DueDate = deadline
 	task.ScheduledDate = scheduled
+	task.Recurrence = recurrence
+	task.BlockedBy = blockedBy
+	task.Blocks = blocks
+	task.SetEstimate(estimate)
+	task.Points = points
+	if len(blockedBy) > 0 || len(blocks) > 0 {
+		task.TypedTitle = title
+	}
 
 	return task
 }
 
+// ExtractDependenciesFromTitle extracts task relations (after:id and blocks:id) from title.
+// Both accept a comma separated list of task ID prefixes. Tokens which can't be task IDs,
+// like after:lunch or after:0, stay in the title.
+// It returns cleaned title, IDs this task waits for, and IDs which wait for this task.
+func ExtractDependenciesFromTitle(title string) (string, []string, []string) {
+	return extractDependencies(title, looksLikeTaskID)
+}
+
+var (
+	idPrefixRegex = regexp.MustCompile(`^[0-9a-fA-F-]{4,}$`)
+	decimalRegex  = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// looksLikeTaskID reports whether ref can be a task ID prefix. Short prefixes and plain numbers
+// are more likely words, and task IDs share their leading digits anyway.
+func looksLikeTaskID(ref string) bool {
+	return idPrefixRegex.MatchString(ref) && !decimalRegex.MatchString(ref)
+}
+
+func extractDependencies(title string, isRef func(string) bool) (string, []string, []string) {
+	dependencyRegex := regexp.MustCompile(`\s+(after|blocks):(\S+)`)
+
+	var blockedBy, blocks []string
+	cleanTitle := dependencyRegex.ReplaceAllStringFunc(title, func(token string) string {
+		match := dependencyRegex.FindStringSubmatch(token)
+		var refs []string
+		for _, ref := range strings.Split(match[2], ",") {
+			if ref == "" {
+				continue
+			}
+			if !isRef(ref) {
+				return token
+			}
+			refs = append(refs, ref)
+		}
+		if match[1] == "after" {
+			blockedBy = append(blockedBy, refs...)
+		} else {
+			blocks = append(blocks, refs...)
+		}
+		return ""
+	})
+
+	return strings.TrimSpace(cleanTitle), blockedBy, blocks
+}
+
+// ExtractRecurrenceFromTitle extracts recurrence rule (repeat:rule) from title and returns cleaned title and rule.
+// Only the first valid rule is taken. Other repeat: words stay in the title, same as unparsable dates.
+func ExtractRecurrenceFromTitle(title string) (string, string) {
+	repeatRegex := regexp.MustCompile(`\s+repeat:(\S+)`)
+	for _, match := range repeatRegex.FindAllStringSubmatchIndex(title, -1) {
+		rule := title[match[2]:match[3]]
+		if _, err := ParseRecurrence(rule); err != nil {
+			continue
+		}
+		cleanTitle := strings.TrimSpace(title[:match[0]] + title[match[1]:])
+		return cleanTitle, strings.ToLower(rule)
+	}
+	return title, ""
+}
+
 // ExtractProjectsFromTitle extracts project tags (+project) from the end of title and returns cleaned title and projects
 func ExtractProjectsFromTitle(title string) (string, []string) {
 	// Extract project tags only from the end of the string
@@ -322,3 +322,33 @@ func TestExtractScheduledDateFromTitleWithNaturalLanguage(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractRecurrenceFromTitle(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedTitle      string
+		expectedRecurrence string
+	}{
+		{input: "Weekly report repeat:weekly", expectedTitle: "Weekly report", expectedRecurrence: "weekly"},
+		{input: "Gym repeat:mon,wed,fri +health", expectedTitle: "Gym +health", expectedRecurrence: "mon,wed,fri"},
+		{input: "Water plants repeat:+3d", expectedTitle: "Water plants", expectedRecurrence: "+3d"},
+		{input: "Invalid repeat:sometimes", expectedTitle: "Invalid repeat:sometimes", expectedRecurrence: ""},
+		{input: "No recurrence", expectedTitle: "No recurrence", expectedRecurrence: ""},
+		{input: "x repeat:daily repeat:bogus", expectedTitle: "x repeat:bogus", expectedRecurrence: "daily"},
+		{input: "x repeat:bogus repeat:daily", expectedTitle: "x repeat:bogus", expectedRecurrence: "daily"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			title, recurrence := ExtractRecurrenceFromTitle(tt.input)
+			require.Equal(t, tt.expectedTitle, title)
+			require.Equal(t, tt.expectedRecurrence, recurrence)
+		})
+	}
+
+	task := ParseTask("Weekly report repeat:weekly due:friday +work")
+	require.Equal(t, "Weekly report", task.Title)
+	require.Equal(t, "weekly", task.Recurrence)
+	require.NotNil(t, task.DueDate)
+	require.Equal(t, []string{"work"}, task.Projects)
+}
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TimeEntry is an interval of work on a task. Entries are recorded while the task is DOING.
+type TimeEntry struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"` // nil while the timer is running
+}
+
+// startTimer opens a time entry, unless one is already running
+func (t *Task) startTimer(now time.Time) {
+	if t.RunningTimer() != nil {
+		return
+	}
+	t.TimeLog = append(t.TimeLog, TimeEntry{Start: now})
+}
+
+// stopTimer closes the running time entry
+func (t *Task) stopTimer(now time.Time) {
+	if entry := t.RunningTimer(); entry != nil {
+		entry.End = &now
+	}
+}
+
+// RunningTimer returns the open time entry of the task, or nil if no timer is running
+func (t *Task) RunningTimer() *TimeEntry {
+	for i := range t.TimeLog {
+		if t.TimeLog[i].End == nil {
+			return &t.TimeLog[i]
+		}
+	}
+	return nil
+}
+
+// TrackedTime returns the time logged on the task between from and to.
+// A running timer counts until now.
+func (t *Task) TrackedTime(from, to time.Time) time.Duration {
+	var total time.Duration
+	for _, entry := range t.TimeLog {
+		total += overlap(entry, from, to)
+	}
+	return total
+}
+
+// TotalTrackedTime returns all time logged on the task
+func (t *Task) TotalTrackedTime() time.Duration {
+	return t.TrackedTime(time.Time{}, time.Now())
+}
+
+func overlap(entry TimeEntry, from, to time.Time) time.Duration {
+	end := time.Now()
+	if entry.End != nil {
+		end = *entry.End
+	}
+	start, end := maxTime(entry.Start, from), minTime(end, to)
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// TrackedTimeByDay returns the time logged on the tasks per local day ("2006-01-02"),
+// splitting entries which span midnight
+func TrackedTimeByDay(tasks []Task, from, to time.Time) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for _, task := range tasks {
+		for _, entry := range task.TimeLog {
+			start, end := entry.Start, time.Now()
+			if entry.End != nil {
+				end = *entry.End
+			}
+			start, end = maxTime(start, from).Local(), minTime(end, to)
+
+			for start.Before(end) {
+				day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+				segmentEnd := minTime(day.AddDate(0, 0, 1), end)
+				result[day.Format("2006-01-02")] += segmentEnd.Sub(start)
+				start = segmentEnd
+			}
+		}
+	}
+	return result
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// TimeSummary is the tracked time of one group in a time report
+type TimeSummary struct {
+	Key      string
+	Duration time.Duration
+}
+
+// SummarizeTrackedTime totals the time logged between from and to, grouped by
+// "project", "task" or "day". Tasks in several projects count for each of them.
+func SummarizeTrackedTime(tasks []Task, from, to time.Time, by string) ([]TimeSummary, error) {
+	totals := make(map[string]time.Duration)
+	switch by {
+	case "project":
+		for _, task := range tasks {
+			d := task.TrackedTime(from, to)
+			if d == 0 {
+				continue
+			}
+			if len(task.Projects) == 0 {
+				totals["(no project)"] += d
+			}
+			for _, project := range task.Projects {
+				totals["+"+project] += d
+			}
+		}
+	case "task":
+		for _, task := range tasks {
+			if d := task.TrackedTime(from, to); d > 0 {
+				totals[task.Title] += d
+			}
+		}
+	case "day":
+		totals = TrackedTimeByDay(tasks, from, to)
+	default:
+		return nil, fmt.Errorf("unknown grouping: %s (expected project, task or day)", by)
+	}
+
+	summaries := make([]TimeSummary, 0, len(totals))
+	for key, d := range totals {
+		summaries = append(summaries, TimeSummary{Key: key, Duration: d})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if by == "day" {
+			return summaries[i].Key < summaries[j].Key
+		}
+		if summaries[i].Duration != summaries[j].Duration {
+			return summaries[i].Duration > summaries[j].Duration
+		}
+		return summaries[i].Key < summaries[j].Key
+	})
+	return summaries, nil
+}
+
+// FormatDuration formats a tracked time like 1h05m or 45m
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh%02dm", hours, minutes)
+}
@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetStatusRecordsTimeLog(t *testing.T) {
+	task := NewTask("Billable work")
+
+	task.SetStatus(StatusDOING)
+	require.Len(t, task.TimeLog, 1)
+	require.NotNil(t, task.RunningTimer())
+
+	// Staying in DOING doesn't open another entry
+	task.SetStatus(StatusDOING)
+	require.Len(t, task.TimeLog, 1)
+
+	task.SetStatus(StatusDONE)
+	require.Nil(t, task.RunningTimer())
+	require.NotNil(t, task.TimeLog[0].End)
+
+	task.SetStatus(StatusDOING)
+	require.Len(t, task.TimeLog, 2)
+}
+
+func TestTrackedTime(t *testing.T) {
+	base := time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+	end := base.Add(2 * time.Hour)
+	task := Task{TimeLog: []TimeEntry{{Start: base, End: &end}}}
+
+	require.Equal(t, 2*time.Hour, task.TrackedTime(time.Time{}, base.AddDate(1, 0, 0)))
+	require.Equal(t, time.Hour, task.TrackedTime(base.Add(time.Hour), base.AddDate(0, 0, 1)))
+	require.Equal(t, time.Duration(0), task.TrackedTime(end, end.Add(time.Hour)))
+
+	// A running timer counts until now
+	running := Task{TimeLog: []TimeEntry{{Start: time.Now().Add(-30 * time.Minute)}}}
+	require.InDelta(t, float64(30*time.Minute), float64(running.TotalTrackedTime()), float64(time.Second))
+}
+
+func TestTrackedTimeByDaySplitsAtMidnight(t *testing.T) {
+	start := time.Date(2025, 3, 10, 23, 0, 0, 0, time.Local)
+	end := start.Add(3 * time.Hour)
+	tasks := []Task{{TimeLog: []TimeEntry{{Start: start, End: &end}}}}
+
+	byDay := TrackedTimeByDay(tasks, time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local), time.Date(2025, 4, 1, 0, 0, 0, 0, time.Local))
+	require.Equal(t, map[string]time.Duration{
+		"2025-03-10": time.Hour,
+		"2025-03-11": 2 * time.Hour,
+	}, byDay)
+}
+
+func TestSummarizeTrackedTime(t *testing.T) {
+	start := time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+	oneHour := start.Add(time.Hour)
+	halfHour := start.Add(30 * time.Minute)
+	tasks := []Task{
+		{Title: "Report", Projects: []string{"work"}, TimeLog: []TimeEntry{{Start: start, End: &oneHour}}},
+		{Title: "Review", Projects: []string{"work", "oss"}, TimeLog: []TimeEntry{{Start: start, End: &halfHour}}},
+		{Title: "Dishes", TimeLog: []TimeEntry{{Start: start, End: &halfHour}}},
+		{Title: "Idle"},
+	}
+	from, to := start.AddDate(0, 0, -1), start.AddDate(0, 0, 1)
+
+	byProject, err := SummarizeTrackedTime(tasks, from, to, "project")
+	require.NoError(t, err)
+	require.Equal(t, []TimeSummary{
+		{Key: "+work", Duration: 90 * time.Minute},
+		{Key: "(no project)", Duration: 30 * time.Minute},
+		{Key: "+oss", Duration: 30 * time.Minute},
+	}, byProject)
+
+	byTask, err := SummarizeTrackedTime(tasks, from, to, "task")
+	require.NoError(t, err)
+	require.Len(t, byTask, 3)
+	require.Equal(t, "Report", byTask[0].Key)
+
+	byDay, err := SummarizeTrackedTime(tasks, from, to, "day")
+	require.NoError(t, err)
+	require.Equal(t, []TimeSummary{{Key: "2025-03-10", Duration: 2 * time.Hour}}, byDay)
+
+	_, err = SummarizeTrackedTime(tasks, from, to, "week")
+	require.Error(t, err)
+}
+
+func TestFormatDuration(t *testing.T) {
+	require.Equal(t, "0m", FormatDuration(10*time.Second))
+	require.Equal(t, "45m", FormatDuration(45*time.Minute))
+	require.Equal(t, "1h05m", FormatDuration(65*time.Minute))
+	require.Equal(t, "12h00m", FormatDuration(12*time.Hour))
+}
+
+func TestInteractiveHeaderShowsRunningTimer(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	task := NewTask("Timed task")
+	require.NoError(t, taskFile.AddTask(task))
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+	require.NotContains(t, model.renderHeader(), "⏱")
+
+	pressKey(t, model, "s")
+	require.Contains(t, model.renderHeader(), "⏱ 0:00:0")
+	require.Contains(t, model.renderHeader(), "Timed task")
+}
+
+func TestInteractiveTimerTicksOnlyWhileRunning(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	require.NoError(t, taskFile.AddTask(NewTask("Timed task")))
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+	require.Nil(t, model.scheduleTimerTick(), "no timer is running")
+
+	// Starting the task starts the tick, once
+	pressKey(t, model, "s")
+	require.True(t, model.ticking)
+	require.Nil(t, model.scheduleTimerTick())
+
+	_, cmd := model.Update(timerTickMsg{})
+	require.NotNil(t, cmd)
+	require.True(t, model.ticking)
+
+	// Once the timer stops, the tick isn't scheduled again
+	pressKey(t, model, "s")
+	_, cmd = model.Update(timerTickMsg{})
+	require.Nil(t, cmd)
+	require.False(t, model.ticking)
+}
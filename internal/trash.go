@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// DeletionTime returns when a task in the trash was deleted.
+// Older trash files stored the deletion time in Updated.
+func (t *Task) DeletionTime() time.Time {
+	if t.DeletedAt != nil {
+		return *t.DeletedAt
+	}
+	return t.Updated
+}
+
+// LoadTrash returns the deleted tasks, most recently deleted first
+func (tf *TaskFile) LoadTrash() ([]Task, error) {
+	tasks, err := tf.loadTrash()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(tasks, func(a, b Task) int {
+		return b.DeletionTime().Compare(a.DeletionTime())
+	})
+	return tasks, nil
+}
+
+func (tf *TaskFile) loadTrash() ([]Task, error) {
+	tasks, err := readTaskLines(tf.getTrashFilePath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+	if tasks == nil {
+		tasks = []Task{}
+	}
+	return tasks, nil
+}
+
+func (tf *TaskFile) saveTrash(tasks []Task) error {
+	return writeTaskLines(tf.getTrashFilePath(), ".trash-*.tmp", tasks)
+}
+
+// RestoreFromTrash moves the tasks with the given IDs from the trash back to the task file.
+// They keep their ID and timestamps. If a task was deleted several times, the latest copy is restored.
+func (tf *TaskFile) RestoreFromTrash(ids []string) ([]Task, error) {
+	lock, err := tf.lock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock task file: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	tasks, err := tf.LoadTasks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+	trash, err := tf.loadTrash()
+	if err != nil {
+		return nil, err
+	}
+	before := slices.Clone(tasks)
+
+	var restored []Task
+	for _, id := range ids {
+		if slices.ContainsFunc(tasks, func(t Task) bool { return t.ID == id }) {
+			return nil, fmt.Errorf("task %s is not deleted", id)
+		}
+
+		latest := -1
+		for i := range trash {
+			if trash[i].ID == id && (latest < 0 || trash[i].DeletionTime().After(trash[latest].DeletionTime())) {
+				latest = i
+			}
+		}
+		if latest < 0 {
+			return nil, fmt.Errorf("task with ID %s not found in trash", id)
+		}
+
+		task := trash[latest]
+		task.DeletedAt = nil
+		tasks = append(tasks, task)
+		restored = append(restored, task)
+		trash = slices.DeleteFunc(trash, func(t Task) bool { return t.ID == id })
+	}
+
+	if err := tf.saveTasksAs(before, tasks, JournalRestored, JournalDeleted); err != nil {
+		return nil, fmt.Errorf("failed to save tasks: %w", err)
+	}
+	if err := tf.saveTrash(trash); err != nil {
+		return nil, fmt.Errorf("failed to save trash: %w", err)
+	}
+	return restored, nil
+}
+
+// PurgeTrash permanently removes the tasks deleted more than olderThan ago.
+// Zero removes all of them. It returns the number of removed tasks.
+func (tf *TaskFile) PurgeTrash(olderThan time.Duration) (int, error) {
+	lock, err := tf.lock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock task file: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	trash, err := tf.loadTrash()
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	kept := slices.DeleteFunc(slices.Clone(trash), func(t Task) bool {
+		return !t.DeletionTime().After(cutoff)
+	})
+	if len(kept) == len(trash) {
+		return 0, nil
+	}
+
+	if err := tf.saveTrash(kept); err != nil {
+		return 0, fmt.Errorf("failed to save trash: %w", err)
+	}
+	return len(trash) - len(kept), nil
+}
+
+var ageRegex = regexp.MustCompile(`^(\d+)([dw])$`)
+
+// ParseAge parses an age like 90d, 2w or a Go duration like 12h
+func ParseAge(s string) (time.Duration, error) {
+	if m := ageRegex.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		days := n
+		if m[2] == "w" {
+			days = n * 7
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age: %s (expected e.g. 90d, 2w or 12h)", s)
+	}
+	return d, nil
+}
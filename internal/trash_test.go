@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreFromTrashKeepsIDAndTimestamps(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	task := NewTask("Deleted task")
+	task.Created = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	task.Updated = time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC)
+	require.NoError(t, taskFile.AddTask(task))
+	require.NoError(t, taskFile.DeleteTask(task.ID))
+
+	trash, err := taskFile.LoadTrash()
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.NotNil(t, trash[0].DeletedAt)
+	require.True(t, trash[0].Updated.Equal(task.Updated), "deletion must not overwrite Updated")
+
+	restored, err := taskFile.RestoreFromTrash([]string{task.ID})
+	require.NoError(t, err)
+	require.Len(t, restored, 1)
+
+	tasks, err := taskFile.LoadTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Equal(t, task.ID, tasks[0].ID)
+	require.True(t, tasks[0].Created.Equal(task.Created))
+	require.True(t, tasks[0].Updated.Equal(task.Updated))
+	require.Nil(t, tasks[0].DeletedAt)
+
+	trash, err = taskFile.LoadTrash()
+	require.NoError(t, err)
+	require.Empty(t, trash)
+
+	// A task which is not deleted can't be restored
+	_, err = taskFile.RestoreFromTrash([]string{task.ID})
+	require.Error(t, err)
+}
+
+func TestLoadTrashWithoutDeletedAt(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+
+	// Older trash files stored the deletion time in Updated
+	old := NewTask("Old trash entry")
+	old.Updated = time.Now().Add(-48 * time.Hour)
+	require.NoError(t, taskFile.saveTrash([]Task{*old}))
+	require.NoError(t, taskFile.saveDeletedTasksToTrash([]Task{*NewTask("New trash entry")}))
+
+	trash, err := taskFile.LoadTrash()
+	require.NoError(t, err)
+	require.Len(t, trash, 2)
+	require.Equal(t, "New trash entry", trash[0].Title, "most recently deleted first")
+	require.True(t, trash[1].DeletionTime().Equal(old.Updated))
+}
+
+func TestPurgeTrash(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+
+	longAgo := time.Now().AddDate(0, 0, -100)
+	recently := time.Now().AddDate(0, 0, -10)
+	oldTask := NewTask("Old")
+	oldTask.DeletedAt = &longAgo
+	recentTask := NewTask("Recent")
+	recentTask.DeletedAt = &recently
+	require.NoError(t, taskFile.saveTrash([]Task{*oldTask, *recentTask}))
+
+	count, err := taskFile.PurgeTrash(90 * 24 * time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	trash, err := taskFile.LoadTrash()
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.Equal(t, "Recent", trash[0].Title)
+
+	count, err = taskFile.PurgeTrash(0)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"90d": 90 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	}
+	for input, want := range tests {
+		got, err := ParseAge(input)
+		require.NoError(t, err, input)
+		require.Equal(t, want, got, input)
+	}
+
+	_, err := ParseAge("soon")
+	require.Error(t, err)
+	_, err = ParseAge("-1h")
+	require.Error(t, err)
+}
+
+func TestInteractiveTrashRestore(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+	task := NewTask("Bring me back")
+	require.NoError(t, taskFile.AddTask(task))
+	require.NoError(t, taskFile.DeleteTask(task.ID))
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+	require.Empty(t, model.tasks)
+
+	pressKey(t, model, "T")
+	require.True(t, model.trashMode)
+	require.Contains(t, model.renderFooter(), "Bring me back")
+
+	pressKey(t, model, "r")
+	require.NoError(t, model.err)
+	require.Len(t, model.tasks, 1)
+	require.Equal(t, task.ID, model.tasks[0].ID)
+	require.Empty(t, model.trash)
+
+	// Restoring can be undone like other changes
+	pressKey(t, model, "q")
+	pressKey(t, model, "u")
+	require.Empty(t, model.tasks)
+}
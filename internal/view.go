@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// View is a named filter defined in a [views.<name>] section of the config file
+type View struct {
+	Filter  string `toml:"filter"`   // Filter expression, see ParseQuery
+	Sort    string `toml:"sort"`     // Sort order, see SortOrders
+	ShowAll bool   `toml:"show_all"` // Include old completed tasks
+}
+
+// SortOrders are the sort orders a view can use. "default" is the order of SortTasks.
+var SortOrders = []string{"default", "due", "scheduled", "created", "updated", "title"}
+
+// ViewNames returns the names of the configured views in alphabetical order
+func (c *Config) ViewNames() []string {
+	names := make([]string, 0, len(c.Views))
+	for name := range c.Views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetView returns the view with the given name, checking its filter and sort order
+func (c *Config) GetView(name string) (View, error) {
+	view, ok := c.Views[strings.TrimPrefix(name, "@")]
+	if !ok {
+		return View{}, fmt.Errorf("view not found: %s (defined views: %s)", name, strings.Join(c.ViewNames(), ", "))
+	}
+	if _, err := ParseQuery(view.Filter); err != nil {
+		return View{}, fmt.Errorf("invalid filter in view %s: %w", name, err)
+	}
+	if err := validateSortOrder(view.Sort); err != nil {
+		return View{}, fmt.Errorf("invalid view %s: %w", name, err)
+	}
+	return view, nil
+}
+
+func validateSortOrder(order string) error {
+	if order == "" {
+		return nil
+	}
+	for _, o := range SortOrders {
+		if o == order {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown sort order: %s (expected %s)", order, strings.Join(SortOrders, ", "))
+}
+
+// SortTasksBy sorts tasks by the given sort order. Ties are broken by the default order,
+// and subtasks are kept right after their parent.
+func SortTasksBy(tasks []Task, order string) {
+	SortTasks(tasks)
+
+	var less func(a, b *Task) bool
+	switch order {
+	case "due":
+		less = func(a, b *Task) bool { return dateBefore(a.DueDate, b.DueDate) }
+	case "scheduled":
+		less = func(a, b *Task) bool { return dateBefore(a.ScheduledDate, b.ScheduledDate) }
+	case "created":
+		less = func(a, b *Task) bool { return a.Created.After(b.Created) }
+	case "updated":
+		less = func(a, b *Task) bool { return a.Updated.After(b.Updated) }
+	case "title":
+		less = func(a, b *Task) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) }
+	default:
+		return
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		// Completed tasks stay at the bottom, as in the default order
+		if tasks[i].IsCompleted() != tasks[j].IsCompleted() {
+			return !tasks[i].IsCompleted()
+		}
+		return less(&tasks[i], &tasks[j])
+	})
+	arrangeTaskTree(tasks)
+}
+
+// dateBefore orders dates ascending, with unset dates last
+func dateBefore(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a != nil && b == nil
+	}
+	return a.Before(*b)
+}
@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeViews(t *testing.T) {
+	var config Config
+	_, err := toml.Decode(`
+[views.today]
+filter = "status:open due:<=today"
+sort = "due"
+
+[views.archive]
+filter = "status:closed"
+show_all = true
+`, &config)
+	require.NoError(t, err)
+	require.Equal(t, []string{"archive", "today"}, config.ViewNames())
+
+	view, err := config.GetView("@today")
+	require.NoError(t, err)
+	require.Equal(t, View{Filter: "status:open due:<=today", Sort: "due"}, view)
+
+	view, err = config.GetView("archive")
+	require.NoError(t, err)
+	require.True(t, view.ShowAll)
+}
+
+func TestGetViewErrors(t *testing.T) {
+	config := &Config{Views: map[string]View{
+		"bad-filter": {Filter: "prio:<=1"},
+		"bad-sort":   {Sort: "random"},
+	}}
+
+	_, err := config.GetView("missing")
+	require.ErrorContains(t, err, "view not found")
+	_, err = config.GetView("bad-filter")
+	require.Error(t, err)
+	_, err = config.GetView("bad-sort")
+	require.ErrorContains(t, err, "unknown sort order")
+}
+
+func TestSortTasksBy(t *testing.T) {
+	now := time.Now()
+	soon := now.AddDate(0, 0, 1)
+	later := now.AddDate(0, 0, 5)
+
+	tasks := []Task{
+		{ID: "1", Title: "No due", Status: StatusTODO, Priority: "A"},
+		{ID: "2", Title: "Due later", Status: StatusTODO, DueDate: &later},
+		{ID: "3", Title: "Done", Status: StatusDONE, DueDate: &soon},
+		{ID: "4", Title: "Due soon", Status: StatusTODO, DueDate: &soon},
+		{ID: "5", Title: "Subtask", Status: StatusTODO, ParentID: "2"},
+	}
+
+	SortTasksBy(tasks, "due")
+
+	var titles []string
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+	}
+	require.Equal(t, []string{"Due soon", "Due later", "Subtask", "No due", "Done"}, titles)
+}
+
+func TestInteractiveViews(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("HOME", configHome)
+	configPath, err := UserConfigPath()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0755))
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+[views.blocked]
+filter = "status:WAITING"
+`), 0644))
+
+	taskFile := NewTaskFileForTesting(t)
+	waiting := NewTask("Waiting task")
+	waiting.Status = StatusWAITING
+	require.NoError(t, taskFile.AddTasks([]Task{*NewTask("Open task"), *waiting}))
+
+	model, err := NewInteractiveTaskListWithFilter(taskFile, "")
+	require.NoError(t, err)
+	require.Len(t, model.tasks, 2)
+
+	require.Error(t, model.SetView("missing"))
+
+	// v opens the view selector, j moves to the first view
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	require.True(t, model.viewSelectMode)
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.False(t, model.viewSelectMode)
+	require.Equal(t, "blocked", model.viewName)
+	require.Len(t, model.tasks, 1)
+	require.Equal(t, "Waiting task", model.tasks[0].Title)
+	require.Contains(t, model.View(), "View: @blocked")
+
+	// The view survives a reload
+	require.NoError(t, model.ReloadTasks())
+	require.Len(t, model.tasks, 1)
+
+	require.NoError(t, model.SetView(""))
+	require.Len(t, model.tasks, 2)
+}
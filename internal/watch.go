@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// fileState identifies a version of the task file
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func (s fileState) equal(other fileState) bool {
+	return s.exists == other.exists && s.modTime.Equal(other.modTime) && s.size == other.size
+}
+
+func (tf *TaskFile) stat() fileState {
+	info, err := os.Stat(tf.Path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
+
+// Watch reports changes of the task file on the returned channel until ctx is done.
+// The file is polled, as every save replaces it by a rename which breaks inotify style watches.
+// Several changes between two receives are reported once.
+func (tf *TaskFile) Watch(ctx context.Context, interval time.Duration) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+	last := tf.stat()
+
+	go func() {
+		defer close(changes)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				current := tf.stat()
+				if current.equal(last) {
+					continue
+				}
+				last = current
+				select {
+				case changes <- struct{}{}:
+				default:
+					// A change is already pending
+				}
+			}
+		}
+	}()
+
+	return changes
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchReportsChanges(t *testing.T) {
+	taskFile := NewTaskFileForTesting(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := taskFile.Watch(ctx, 10*time.Millisecond)
+
+	// Creating the file is a change
+	require.NoError(t, taskFile.AddTask(NewTask("First")))
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a change after creating the file")
+	}
+
+	// Nothing happens without writes
+	select {
+	case <-changes:
+		t.Fatal("unexpected change")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, taskFile.AddTask(NewTask("Second")))
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a change after adding a task")
+	}
+
+	cancel()
+	for range changes {
+		// Drain until the watcher stops
+	}
+}